@@ -0,0 +1,56 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAdmissionRejected is returned by Enqueue of a queue WithAdmissionController if the controller turned the task away,
+// and for a task submitted to a pool whose PoolOptions.AdmissionController did. errors.As with *AdmissionError gives the reason.
+var ErrAdmissionRejected = errors.New("admission rejected")
+
+// AdmissionError is ErrAdmissionRejected carrying the reason from the controller.
+type AdmissionError struct {
+	// Reason is the error message from the controller.
+	Reason string
+	err    error
+}
+
+func (e *AdmissionError) Error() string {
+	return ErrAdmissionRejected.Error() + ": " + e.Reason
+}
+
+// Is makes errors.Is(err, ErrAdmissionRejected) work.
+func (e *AdmissionError) Is(target error) bool {
+	return target == ErrAdmissionRejected
+}
+
+// Unwrap returns the error from the controller.
+func (e *AdmissionError) Unwrap() error {
+	return e.err
+}
+
+// AdmissionController decides whether a task can be enqueued, based on system load, tenant quotas, maintenance mode etc.
+// it returns nil to admit the task, or an error telling why it's rejected.
+// it guards a DurableQueue (see WithAdmissionController), or a Pool (see PoolOptions), tasks started on their own are not admitted.
+type AdmissionController func(desc TaskDescriptor) error
+
+// WithAdmissionController returns a DurableQueue which runs controller before every Enqueue into queue,
+// a rejected task is not enqueued, and Enqueue returns an *AdmissionError.
+// a task coming back through Release is already admitted, and isn't checked again.
+func WithAdmissionController(queue DurableQueue, controller AdmissionController) DurableQueue {
+	return &admissionQueue{DurableQueue: queue, controller: controller}
+}
+
+type admissionQueue struct {
+	DurableQueue
+	controller AdmissionController
+}
+
+// Enqueue implements DurableQueue.
+func (q *admissionQueue) Enqueue(ctx context.Context, desc TaskDescriptor) (TaskDescriptor, error) {
+	if err := q.controller(desc); err != nil {
+		return TaskDescriptor{}, &AdmissionError{Reason: err.Error(), err: err}
+	}
+	return q.DurableQueue.Enqueue(ctx, desc)
+}
@@ -0,0 +1,64 @@
+package asynctask_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var errQuotaExceeded = errors.New("tenant quota exceeded")
+
+func TestAdmissionController(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	memoryQueue := asynctask.NewMemoryQueue()
+	queue := asynctask.WithAdmissionController(memoryQueue, func(desc asynctask.TaskDescriptor) error {
+		if string(desc.Payload) == "noisy" && memoryQueue.Len() >= 1 {
+			return errQuotaExceeded
+		}
+		return nil
+	})
+
+	_, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("noisy")})
+	assert.NoError(t, err)
+	_, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("noisy")})
+	assert.True(t, errors.Is(err, asynctask.ErrAdmissionRejected), "expecting ErrAdmissionRejected")
+	assert.True(t, errors.Is(err, errQuotaExceeded), "expecting controller error")
+	var admissionErr *asynctask.AdmissionError
+	assert.True(t, errors.As(err, &admissionErr))
+	assert.Equal(t, "tenant quota exceeded", admissionErr.Reason)
+	assert.Equal(t, 1, memoryQueue.Len())
+
+	// other tasks are admitted.
+	_, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("quiet")})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, memoryQueue.Len())
+}
+
+func TestPoolAdmissionController(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, AdmissionController: func(desc asynctask.TaskDescriptor) error {
+		if desc.Labels["tenant"] == "noisy" {
+			return errQuotaExceeded
+		}
+		return nil
+	}})
+	defer pool.Close()
+
+	rejected := pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("tenant", "noisy"))
+	_, err := rejected.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrAdmissionRejected), "expecting ErrAdmissionRejected")
+	assert.Equal(t, asynctask.StateFailed, rejected.State())
+	assert.Zero(t, pool.Stats().Started)
+
+	_, err = pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("tenant", "quiet")).Wait(ctx)
+	assert.NoError(t, err)
+}
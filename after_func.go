@@ -0,0 +1,13 @@
+//go:build go1.21
+// +build go1.21
+
+package asynctask
+
+import "context"
+
+// afterFunc arranges to call f in its own routine once ctx is done,
+// stop returns true if it stopped f from being run.
+// it relies on context.AfterFunc, no routine is parked while waiting.
+func afterFunc(ctx context.Context, f func()) (stop func() bool) {
+	return context.AfterFunc(ctx, f)
+}
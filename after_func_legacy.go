@@ -0,0 +1,38 @@
+//go:build !go1.21
+// +build !go1.21
+
+package asynctask
+
+import (
+	"context"
+	"sync"
+)
+
+// afterFunc arranges to call f in its own routine once ctx is done,
+// stop returns true if it stopped f from being run.
+// context.AfterFunc is not available before go1.21, a routine is parked to watch ctx.
+func afterFunc(ctx context.Context, f func()) (stop func() bool) {
+	if ctx.Done() == nil {
+		// never canceled, nothing to watch.
+		return func() bool { return true }
+	}
+
+	once := sync.Once{}
+	stopCh := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			once.Do(f)
+		case <-stopCh:
+		}
+	}()
+
+	return func() bool {
+		stopped := false
+		once.Do(func() {
+			stopped = true
+			close(stopCh)
+		})
+		return stopped
+	}
+}
@@ -0,0 +1,36 @@
+package asynctask
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AggregateError collects errors from multiple tasks.
+type AggregateError struct {
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d errors: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the collected errors, errors.Is and errors.As look into each of them (go1.20 and later).
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
+}
+
+// aggregate returns nil if errs is empty, otherwise an AggregateError.
+func aggregate(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &AggregateError{Errors: errs}
+}
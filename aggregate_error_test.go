@@ -0,0 +1,20 @@
+package asynctask_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateError(t *testing.T) {
+	t.Parallel()
+
+	single := &asynctask.AggregateError{Errors: []error{errors.New("first")}}
+	assert.Equal(t, "first", single.Error())
+
+	multiple := &asynctask.AggregateError{Errors: []error{errors.New("first"), asynctask.ErrCanceled}}
+	assert.Equal(t, "2 errors: first; canceled", multiple.Error())
+	assert.Equal(t, []error{errors.New("first"), asynctask.ErrCanceled}, multiple.Unwrap())
+}
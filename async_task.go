@@ -5,8 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -25,9 +25,24 @@ const StateFailed State = "Failed"
 // StateCanceled indicate task got canceled.
 const StateCanceled State = "Canceled"
 
+// StateQueued indicate task is waiting for a worker.
+const StateQueued State = "Queued"
+
+// StateRetrying indicate task failed an attempt and is waiting to try again.
+const StateRetrying State = "Retrying"
+
+// StatePaused indicate task is suspended, and will continue running once resumed, see Pause.
+const StatePaused State = "Paused"
+
+// StateScheduled indicate task is waiting for its start time.
+const StateScheduled State = "Scheduled"
+
+// StateSkipped indicate task never ran, and won't, because a condition it depends on is not met, see Graph.When.
+const StateSkipped State = "Skipped"
+
 // IsTerminalState tells whether the task finished
 func (s State) IsTerminalState() bool {
-	return s != StateRunning
+	return s == StateCompleted || s == StateFailed || s == StateCanceled || s == StateSkipped
 }
 
 // AsyncFunc is a function interface this asyncTask accepts.
@@ -36,105 +51,394 @@ type AsyncFunc func(context.Context) (interface{}, error)
 // ErrPanic is returned if panic cought in the task
 var ErrPanic = errors.New("panic")
 
-// ErrCanceled is returned if a cancel is triggered
-var ErrCanceled = errors.New("canceled")
+// ErrCanceled is returned if a cancel is triggered,
+// it satisfies errors.Is(err, context.Canceled).
+var ErrCanceled error = &sentinelError{msg: "canceled", err: context.Canceled}
+
+// ErrGoexit is returned if the task function called runtime.Goexit (t.FailNow, t.Fatal etc.) instead of returning.
+// os.Exit can't be intercepted, the process is gone along with the task.
+var ErrGoexit = errors.New("task function called runtime.Goexit")
+
+// ErrWaitTimeout is returned from WaitWithTimeout if the wait timed out while task is still running,
+// it satisfies errors.Is(err, context.DeadlineExceeded).
+var ErrWaitTimeout = fmt.Errorf("wait timeout, task still running: %w", context.DeadlineExceeded)
 
 // TaskStatus is a handle to the running function.
 // which you can use to wait, cancel, get the result.
 type TaskStatus struct {
-	state      State
+	// checkpoints is number of Checkpoint calls, first field for 64-bit alignment of atomic access.
+	checkpoints uint64
+
+	mutex sync.Mutex
+	state State
+	// final holds *taskOutcome once task finished, for waiters to read it without the lock, see loadOutcome.
+	final      atomic.Value
 	result     interface{}
 	err        error
 	cancelFunc context.CancelFunc
-	waitGroup  *sync.WaitGroup
+	// done is closed once task reach terminal state.
+	done      chan struct{}
+	callbacks []func()
+	sink      EventSink
+	options   *taskOptions
+	released  bool
+	attempt   int
+	// errorClass is class of err, see WithErrorClassifier.
+	errorClass ErrorClass
+	// timeout is context of a running task started WithTimeout.
+	timeout *timeoutContext
+	// attemptTimeout is context of a running attempt bounded by Timeouts.Attempt, see startAttemptTimeout.
+	attemptTimeout *timeoutContext
+	// pool is the pool running the task, nil if started on its own.
+	pool *Pool
+	// spawned is number of tasks spawned from this one, see Spawn.
+	spawned int
+	// staged is set once task function run a stage, which retries on its own, see RunStage.
+	staged bool
+	// handles is number of live handles, see Handle.
+	handles int
+	// subscribers receive state changes, see StateChanges.
+	subscribers []chan State
+	// resume is set while a pause is requested, and closed on Resume, see Pause.
+	resume chan struct{}
+	// usage is sampled around task function, see WithResourceSampling.
+	usage ResourceUsage
+	// cpuTime is CPU time of task function, see WithCPUTime.
+	cpuTime time.Duration
+	// inline is set for a task which a waiter may run, see WithInline.
+	inline *inlineRun
+	// waitingOn is the task this one's function waits on, blockedOn is the mutex it waits for, see SetStrictWait.
+	// both guarded by waitGraph.
+	waitingOn *TaskStatus
+	blockedOn *taskMutex
+	// trace is recorded for a sample of tasks, see WithTraceSampling.
+	trace *taskTrace
+	// function is the running task function, tracked for SetCancelAudit.
+	function *functionRun
+
+	progress   float64
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
 }
 
 // State return state of the task.
 func (t *TaskStatus) State() State {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 	return t.state
 }
 
 // Cancel abort the task execution
 // !! only if the function provided handles context cancel.
 func (t *TaskStatus) Cancel() {
-	if !t.state.IsTerminalState() {
+	t.cancelWithError(ErrCanceled)
+}
+
+// CancelWithCause abort the task like Cancel, Wait returns ErrCanceled wrapping cause,
+// e.g. to tell an operator abort from a shutdown.
+func (t *TaskStatus) CancelWithCause(cause error) {
+	t.cancelWithError(newCanceledError(cause))
+}
+
+// cancelWithError abort the task and record err as the task error.
+func (t *TaskStatus) cancelWithError(err error) {
+	if !t.State().IsTerminalState() {
 		t.cancelFunc()
 
-		t.finish(StateCanceled, nil, ErrCanceled)
+		t.finish(StateCanceled, nil, err)
+		t.auditCancel()
+	}
+}
+
+// taskOutcome is the outcome of a finished task, immutable once published.
+type taskOutcome struct {
+	result interface{}
+	err    error
+}
+
+// releasedOutcome is published once result of a task is released.
+var releasedOutcome = &taskOutcome{err: ErrResultReleased}
+
+// loadOutcome returns outcome of the task without locking nor allocating, nil if task is not finished,
+// or started WithReleaseOnWait, in which case a wait has to go through the lock.
+func (t *TaskStatus) loadOutcome() *taskOutcome {
+	o, _ := t.final.Load().(*taskOutcome)
+	return o
+}
+
+// publishOutcome make outcome of a finished task visible to loadOutcome, mutex should be held.
+func (t *TaskStatus) publishOutcome() {
+	switch {
+	case t.released:
+		t.final.Store(releasedOutcome)
+	case !t.options.releaseOnWait:
+		t.final.Store(&taskOutcome{result: t.result, err: t.err})
+	}
+}
+
+// outcome returns the recorded result and error of the task.
+func (t *TaskStatus) outcome() (interface{}, error) {
+	if o := t.loadOutcome(); o != nil {
+		return o.result, o.err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.released {
+		return nil, ErrResultReleased
+	}
+	return t.result, t.err
+}
+
+// waitOutcome returns outcome to a waiter, and release the result if task asked to.
+func (t *TaskStatus) waitOutcome() (interface{}, error) {
+	if o := t.loadOutcome(); o != nil {
+		return o.result, o.err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.released {
+		return nil, ErrResultReleased
 	}
+	if t.options.releaseOnWait {
+		t.released = true
+		defer func() { t.result = nil }()
+	}
+	return t.result, t.err
 }
 
 // Wait block current thread/routine until task finished or failed.
 // context passed in can terminate the wait, through context cancellation
 // but won't terminate the task (unless it's same context)
 func (t *TaskStatus) Wait(ctx context.Context) (interface{}, error) {
-	// return immediately if task already in terminal state.
-	if t.state.IsTerminalState() {
-		return t.result, t.err
+	// return immediately if task already in terminal state, without locking if possible.
+	if o := t.loadOutcome(); o != nil {
+		return o.result, o.err
+	}
+	if t.State().IsTerminalState() || t.runInline() {
+		return t.waitOutcome()
 	}
 
-	ch := make(chan interface{})
-	go func() {
-		t.waitGroup.Wait()
-		close(ch)
-	}()
+	end, err := t.beginWait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	select {
+	case <-t.done:
+		return t.waitOutcome()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// waitFinished is Wait for waits the library makes on behalf of its user, e.g. WaitAll or Registry.Drain:
+// it doesn't release the result of a task started WithReleaseOnWait, which is left for the user's own Wait.
+func (t *TaskStatus) waitFinished(ctx context.Context) (interface{}, error) {
+	if o := t.loadOutcome(); o != nil {
+		return o.result, o.err
+	}
+	if t.State().IsTerminalState() || t.runInline() {
+		return t.outcome()
+	}
+
+	end, err := t.beginWait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
 
 	select {
-	case <-ch:
-		return t.result, t.err
+	case <-t.done:
+		return t.outcome()
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
+// TryResult returns outcome of the task without blocking, done is false if task is not finished yet.
+// it doesn't lock nor allocate on a finished task, unless started WithReleaseOnWait, whose result it releases like Wait.
+func (t *TaskStatus) TryResult() (result interface{}, done bool, err error) {
+	if o := t.loadOutcome(); o != nil {
+		return o.result, true, o.err
+	}
+	if !t.State().IsTerminalState() {
+		return nil, false, nil
+	}
+	result, err = t.waitOutcome()
+	return result, true, err
+}
+
+// Await is Wait which reports failure of the task and interruption of the wait apart:
+// taskErr is the error the task finished with, waitErr is set (to ctx.Err()) only if ctx is done before the task finished,
+// in which case task may still be running, and result and taskErr are nil.
+func (t *TaskStatus) Await(ctx context.Context) (result interface{}, taskErr error, waitErr error) {
+	t.runInline()
+	end, err := t.beginWait(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer end()
+
+	select {
+	case <-t.done:
+		result, taskErr = t.waitOutcome()
+		return result, taskErr, nil
+	case <-ctx.Done():
+		// task may finish at the same moment, report it if so.
+		if t.State().IsTerminalState() {
+			result, taskErr = t.waitOutcome()
+			return result, taskErr, nil
+		}
+		return nil, nil, ctx.Err()
+	}
+}
+
 // WaitWithTimeout block current thread/routine until task finished or failed, or exceed the duration specified.
-// timeout only stop waiting, taks will remain running.
+// timeout only stop waiting, taks will remain running, and ErrWaitTimeout is returned.
+// timeouts of concurrent waits are coalesced into shared timers, one may end up to 1/64 of it (at most 50ms) late.
 func (t *TaskStatus) WaitWithTimeout(ctx context.Context, timeout time.Duration) (interface{}, error) {
 	// return immediately if task already in terminal state.
-	if t.state.IsTerminalState() {
-		return t.result, t.err
+	if o := t.loadOutcome(); o != nil {
+		return o.result, o.err
+	}
+	if t.State().IsTerminalState() || t.runInline() {
+		return t.waitOutcome()
+	}
+
+	end, err := t.beginWait(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer end()
+
+	expired, release := waitTimeouts.acquire(timeout)
+	defer release()
 
-	ctx, cancelFunc := context.WithTimeout(ctx, timeout)
-	defer cancelFunc()
+	select {
+	case <-t.done:
+		return t.waitOutcome()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-expired:
+		if t.State().IsTerminalState() {
+			return t.waitOutcome()
+		}
+		return nil, ErrWaitTimeout
+	}
+}
+
+// OnDone register a callback to run once the task reach terminal state,
+// callback run right away if task already finished.
+// callback runs on the routine which finished the task, keep it short or start your own routine.
+func (t *TaskStatus) OnDone(callback func(result interface{}, err error)) {
+	t.onDone(func() {
+		callback(t.outcome())
+	})
+}
+
+// onDone runs callback once the task finished, right away if it already did, nil callback is ignored.
+func (t *TaskStatus) onDone(callback func()) {
+	if callback == nil {
+		return
+	}
+	t.mutex.Lock()
+	if !t.state.IsTerminalState() {
+		t.callbacks = append(t.callbacks, callback)
+		t.mutex.Unlock()
+		return
+	}
+	t.mutex.Unlock()
 
-	return t.Wait(ctx)
+	t.safeCall(callback)
 }
 
 // NewCompletedTask returns a Completed task, with result=nil, error=nil
 func NewCompletedTask() *TaskStatus {
-	return &TaskStatus{
-		state:  StateCompleted,
-		result: nil,
-		err:    nil,
-		// nil cancelFunc and waitGroup should be protected with IsTerminalState()
+	return newFinishedTask(StateCompleted, nil, nil, &taskOptions{})
+}
+
+// newFinishedTask returns a task in terminal state with result and err, e.g. restored from a checkpoint.
+func newFinishedTask(state State, result interface{}, err error, options *taskOptions) *TaskStatus {
+	done := make(chan struct{})
+	close(done)
+	tsk := &TaskStatus{
+		state:  state,
+		result: result,
+		err:    err,
+		// nil cancelFunc should be protected with IsTerminalState()
 		cancelFunc: nil,
-		waitGroup:  nil,
+		done:       done,
+		options:    options,
 	}
+	tsk.publishOutcome()
+	return tsk
 }
 
-// Start run a async function and returns you a handle which you can Wait or Cancel.
-// context passed in may impact task lifetime (from context cancellation)
-func Start(ctx context.Context, task AsyncFunc) *TaskStatus {
-	ctx, cancel := context.WithCancel(ctx)
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	record := &TaskStatus{
+// newRunningTask returns a Running task, cancel is invoked when task get canceled.
+func newRunningTask(cancel context.CancelFunc, options *taskOptions) *TaskStatus {
+	sink := getEventSink()
+	if options.parent != nil {
+		sink = options.parent.sink
+	}
+	return &TaskStatus{
 		state:      StateRunning,
 		result:     nil,
 		cancelFunc: cancel,
-		waitGroup:  wg,
+		done:       make(chan struct{}),
+		sink:       sink,
+		options:    options,
+		trace:      sampleTrace(options),
+		createdAt:  time.Now(),
 	}
+}
+
+// failOnContextDone fail a task which is not yet running once its context is done, so it never runs.
+// the returned stop should be called before running the task, and it returns false if the task was failed.
+func failOnContextDone(parentCtx, taskCtx context.Context, record *TaskStatus) (stop func() bool) {
+	return afterFunc(taskCtx, func() {
+		err := parentCtx.Err()
+		if err == nil {
+			// canceled through the handle, Cancel takes care of the state.
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = ErrDeadlineBeforeStart
+		}
+		record.finish(StateFailed, nil, withCause(parentCtx, err))
+	})
+}
+
+// Start run a async function and returns you a handle which you can Wait or Cancel.
+// context passed in may impact task lifetime (from context cancellation)
+func Start(ctx context.Context, task AsyncFunc, opts ...TaskOption) *TaskStatus {
+	options := newTaskOptions(opts)
+	ctx, release := options.taskContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	record := newRunningTask(cancel, options)
+	record.trackDefault()
+	record.onDone(release)
 
+	if options.inline {
+		record.inline = &inlineRun{ctx: ctx, task: task}
+		go record.runInline()
+		return record
+	}
 	go runAndTrackTask(ctx, record, task)
 
 	return record
 }
 
 // isErrorReallyError do extra error check
-//    - Nil Pointer to a Type (that implement error)
-//    - Zero Value of a Type (that implement error)
+//   - Nil Pointer to a Type (that implement error)
+//   - Zero Value of a Type (that implement error)
 func isErrorReallyError(err error) bool {
+	// context.DeadlineExceeded is a zero value struct, but it's a real error.
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
 	v := reflect.ValueOf(err)
 	if v.Type().Kind() == reflect.Ptr &&
 		v.IsNil() {
@@ -148,35 +452,122 @@ func isErrorReallyError(err error) bool {
 	return true
 }
 
-func runAndTrackTask(ctx context.Context, record *TaskStatus, task func(ctx context.Context) (interface{}, error)) {
-	defer record.waitGroup.Done()
+// runAndTrackTask runs the task function, and record the outcome, returns true if the function panicked.
+// a task calling runtime.Goexit is failed with ErrGoexit, and the routine exits after that.
+func runAndTrackTask(ctx context.Context, record *TaskStatus, task func(ctx context.Context) (interface{}, error)) (panicked bool) {
+	// running is true while task function runs.
+	running := false
 	defer func() {
 		if r := recover(); r != nil {
-			err := fmt.Errorf("Panic cought: %v, StackTrace: %s, %w", r, debug.Stack(), ErrPanic)
-			record.finish(StateFailed, nil, err)
+			panicked = true
+			record.finish(StateFailed, nil, newPanicError(r))
+		} else if running {
+			// neither returned nor panicked, only runtime.Goexit unwinds like that.
+			record.finish(StateFailed, nil, ErrGoexit)
 		}
 	}()
 
-	result, err := task(ctx)
+	record.tracePickedUp()
+	if err := record.options.err; err != nil {
+		record.finish(StateFailed, nil, err)
+		return false
+	}
+
+	release, err := record.acquireLimit(ctx)
+	if err != nil {
+		record.finish(StateFailed, nil, err)
+		return false
+	}
+	defer release()
+
+	taskCtx := ctx
+	ctx, stopTimeout := record.startTimeout(ctx)
+	defer stopTimeout()
+
+	record.markStarted()
+	record.emitStart()
+	stopProbe := record.startProbe(ctx)
+	// deferred as well, in case task function panicked.
+	defer stopProbe()
+
+	record.traceStarted()
+	returned := record.auditFunction()
+	// deferred, so a panic or runtime.Goexit counts as returned too.
+	defer returned()
+	stopSampling := record.sampleUsage()
+	stopCPU := record.measureCPU()
+	// unlock the thread even if task function panicked, a pool worker keeps running on this routine.
+	defer stopCPU()
+	// middlewares run once, around all retries.
+	run := record.wrapMiddleware(func(ctx context.Context) (interface{}, error) {
+		return record.runWithRetry(ctx, task)
+	})
+	running = true
+	result, err := run(withTask(ctx, record))
+	running = false
+	stopProbe()
+	stopCPU()
+	stopSampling()
 
 	if err == nil ||
 		// incase some team use pointer typed error (implement Error() string on a pointer type)
 		// which can break err check (but nil point assigned to error result to non-nil error)
 		// check out TestPointerErrorCase in error_test.go
 		!isErrorReallyError(err) {
+		if err := record.validate(result); err != nil {
+			record.finish(StateFailed, nil, err)
+			return false
+		}
 		record.finish(StateCompleted, result, nil)
-		return
+		return false
 	}
 
 	// err not nil, fail the task
-	record.finish(StateFailed, result, err)
+	record.finish(StateFailed, result, withCause(taskCtx, err))
+	return false
+}
+
+// sentinelError is an error with its own message, wrapping a standard library error.
+type sentinelError struct {
+	msg string
+	err error
+}
+
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
 }
 
 func (t *TaskStatus) finish(state State, result interface{}, err error) {
+	// classifier is user code, keep it out of the lock.
+	class := t.classify(err)
+	t.mutex.Lock()
 	// only update state and result if not yet canceled
-	if !t.state.IsTerminalState() {
-		t.state = state
+	if !t.state.canTransitionTo(state) {
+		t.mutex.Unlock()
+		return
+	}
+	t.state = state
+	if !t.released {
 		t.result = result
-		t.err = err
 	}
+	t.err = err
+	t.errorClass = class
+	t.finishedAt = time.Now()
+	t.publishOutcome()
+	callbacks := t.callbacks
+	t.callbacks = nil
+	close(t.done)
+	t.notifySubscribers(state)
+	t.mutex.Unlock()
+
+	t.emitFinish(state, err)
+	callbacksStart := time.Now()
+	for _, callback := range callbacks {
+		t.safeCall(callback)
+	}
+	t.emitTrace(time.Since(callbacksStart))
 }
@@ -181,3 +181,96 @@ func TestCrazyCase(t *testing.T) {
 		}
 	}
 }
+
+func TestOnDone(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 2*time.Millisecond))
+	done := make(chan interface{}, 1)
+	tsk.OnDone(func(result interface{}, err error) {
+		assert.NoError(t, err)
+		done <- result
+	})
+	assert.Equal(t, 9, <-done)
+
+	// registered after finish, run right away
+	tsk.OnDone(func(result interface{}, err error) {
+		done <- result
+	})
+	assert.Equal(t, 9, <-done)
+
+	// canceled task also notify.
+	tsk = asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	tsk.OnDone(func(result interface{}, err error) {
+		done <- err
+	})
+	tsk.Cancel()
+	assert.Equal(t, asynctask.ErrCanceled, <-done)
+}
+
+func TestAwait(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelWait()
+	result, taskErr, waitErr := tsk.Await(waitCtx)
+	assert.Nil(t, result)
+	assert.NoError(t, taskErr, "task is healthy")
+	assert.Equal(t, context.DeadlineExceeded, waitErr)
+
+	result, taskErr, waitErr = tsk.Await(ctx)
+	assert.Equal(t, 9, result)
+	assert.NoError(t, taskErr)
+	assert.NoError(t, waitErr)
+
+	// task failed with a context error, it's not a wait error.
+	_, taskErr, waitErr = asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, context.DeadlineExceeded
+	}).Await(ctx)
+	assert.Equal(t, context.DeadlineExceeded, taskErr)
+	assert.NoError(t, waitErr)
+}
+
+// not parallel, AllocsPerRun can't run along other tests.
+func TestTryResult(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(5, 10*time.Millisecond))
+	result, done, err := tsk.TryResult()
+	assert.False(t, done)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	result, done, err = tsk.TryResult()
+	assert.True(t, done)
+	assert.Equal(t, 4, result)
+	assert.NoError(t, err)
+
+	// lock and allocation free once finished.
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _, _ = tsk.TryResult()
+		_, _ = tsk.Wait(ctx)
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	// released result is gone for the fast path too.
+	tsk.Release()
+	_, done, err = tsk.TryResult()
+	assert.True(t, done)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+
+	// release on wait goes through the lock, once.
+	tsk = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithReleaseOnWait())
+	_, _ = tsk.Wait(ctx)
+	_, done, err = tsk.TryResult()
+	assert.True(t, done)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+}
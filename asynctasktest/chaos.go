@@ -0,0 +1,109 @@
+// Package asynctasktest provides helpers for testing code built on asynctask.
+package asynctasktest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+)
+
+// ErrInjected is the default error injected by Chaos.
+var ErrInjected = errors.New("chaos: injected error")
+
+// ChaosOptions defines what Chaos injects, rates are between 0 and 1.
+type ChaosOptions struct {
+	// Seed makes the injected faults reproducible, same seed and same call order give same faults.
+	Seed int64
+	// DelayRate is the chance to sleep up to MaxDelay before running the function.
+	DelayRate float64
+	MaxDelay  time.Duration
+	// CancelRate is the chance to cancel the function context, up to MaxDelay after it starts.
+	CancelRate float64
+	// ErrorRate is the chance to fail with Err instead of running the function.
+	ErrorRate float64
+	// Err is the injected error, default to ErrInjected.
+	Err error
+}
+
+// Chaos wraps task functions to inject random delays, cancellations and errors, to verify orchestration handles partial failures.
+// it's for tests only.
+type Chaos struct {
+	options ChaosOptions
+	mutex   sync.Mutex
+	rand    *rand.Rand
+}
+
+// NewChaos returns a Chaos injecting faults per options.
+func NewChaos(options ChaosOptions) *Chaos {
+	if options.Err == nil {
+		options.Err = ErrInjected
+	}
+	return &Chaos{
+		options: options,
+		rand:    rand.New(rand.NewSource(options.Seed)),
+	}
+}
+
+// chaosPlan is the faults decided for one call.
+type chaosPlan struct {
+	delay       time.Duration
+	cancelAfter time.Duration
+	cancel      bool
+	fail        bool
+}
+
+// plan draws faults for one call, always the same number of draws so the sequence is stable.
+func (c *Chaos) plan() chaosPlan {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	draw := func(rate float64) bool { return c.rand.Float64() < rate }
+	duration := func() time.Duration {
+		if c.options.MaxDelay <= 0 {
+			c.rand.Int63()
+			return 0
+		}
+		return time.Duration(c.rand.Int63n(int64(c.options.MaxDelay)))
+	}
+
+	p := chaosPlan{}
+	if draw(c.options.DelayRate) {
+		p.delay = duration()
+	} else {
+		duration()
+	}
+	p.cancel = draw(c.options.CancelRate)
+	p.cancelAfter = duration()
+	p.fail = draw(c.options.ErrorRate)
+	return p
+}
+
+// Wrap returns fn with faults injected.
+func (c *Chaos) Wrap(fn asynctask.AsyncFunc) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		p := c.plan()
+
+		if p.delay > 0 {
+			select {
+			case <-time.After(p.delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if p.fail {
+			return nil, c.options.Err
+		}
+		if p.cancel {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			timer := time.AfterFunc(p.cancelAfter, cancel)
+			defer timer.Stop()
+		}
+		return fn(ctx)
+	}
+}
@@ -0,0 +1,58 @@
+package asynctasktest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/Azure/go-asynctask/asynctasktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForCancel(ctx context.Context) (interface{}, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(50 * time.Millisecond):
+		return "done", nil
+	}
+}
+
+// outcomes runs the function n times through chaos, and collect outcomes.
+func outcomes(t *testing.T, chaos *asynctasktest.Chaos, n int) []string {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFunc()
+
+	var results []string
+	for i := 0; i < n; i++ {
+		result, err := asynctask.Start(ctx, chaos.Wrap(waitForCancel)).Wait(ctx)
+		switch {
+		case err == asynctasktest.ErrInjected:
+			results = append(results, "error")
+		case err == context.Canceled:
+			results = append(results, "canceled")
+		case err != nil:
+			t.Fatalf("unexpected error %v", err)
+		default:
+			results = append(results, result.(string))
+		}
+	}
+	return results
+}
+
+func TestChaos(t *testing.T) {
+	t.Parallel()
+
+	options := asynctasktest.ChaosOptions{Seed: 42, DelayRate: 0.5, MaxDelay: 10 * time.Millisecond, CancelRate: 0.3, ErrorRate: 0.3}
+	first := outcomes(t, asynctasktest.NewChaos(options), 20)
+	assert.Equal(t, first, outcomes(t, asynctasktest.NewChaos(options), 20), "same seed, same faults")
+	assert.Contains(t, first, "error")
+	assert.Contains(t, first, "canceled")
+	assert.Contains(t, first, "done")
+
+	// no rates, no faults.
+	for _, outcome := range outcomes(t, asynctasktest.NewChaos(asynctasktest.ChaosOptions{}), 5) {
+		assert.Equal(t, "done", outcome)
+	}
+}
@@ -0,0 +1,63 @@
+package asynctasktest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+)
+
+// importing the package turns on tracking of every task, for VerifyNone.
+func init() {
+	if asynctask.DefaultRegistry() == nil {
+		asynctask.SetDefaultRegistry(asynctask.NewRegistry())
+	}
+}
+
+// TestingT is the part of testing.TB VerifyNone needs.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// VerifyNoneOptions defines options for VerifyNone function
+type VerifyNoneOptions struct {
+	// MaxWait is how long tasks have to finish before they count as leaked, default to 1 second.
+	MaxWait time.Duration
+}
+
+// VerifyNone fails the test if any task is still not finished, like goleak does for routines,
+// catching forgotten Wait and missing Cancel; call it at the end of a test, e.g. defer asynctasktest.VerifyNone(t, nil).
+// tasks are tracked in asynctask.DefaultRegistry, which importing this package sets up.
+// tasks of other tests running in parallel count as well, so use it in tests which aren't parallel, or in TestMain.
+func VerifyNone(t TestingT, options *VerifyNoneOptions) {
+	t.Helper()
+	registry := asynctask.DefaultRegistry()
+	if registry == nil {
+		t.Errorf("asynctasktest: tasks are not tracked, asynctask.SetDefaultRegistry was set to nil")
+		return
+	}
+
+	maxWait := time.Second
+	if options != nil && options.MaxWait > 0 {
+		maxWait = options.MaxWait
+	}
+
+	deadline := time.Now().Add(maxWait)
+	for registry.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	leaked := registry.Tasks()
+	if len(leaked) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(leaked))
+	for _, tsk := range leaked {
+		info := tsk.Info()
+		lines = append(lines, fmt.Sprintf("  %q %s, created %s ago, labels %v", info.Name, info.State, time.Since(info.CreatedAt).Round(time.Millisecond), info.Labels))
+	}
+	t.Errorf("asynctasktest: found %d unfinished tasks:\n%s", len(leaked), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,48 @@
+package asynctasktest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/Azure/go-asynctask/asynctasktest"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func blockUntilCanceled(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// not parallel, VerifyNone sees tasks of all tests.
+func TestVerifyNone(t *testing.T) {
+	ctx := context.Background()
+
+	tsk := asynctask.Start(ctx, blockUntilCanceled, asynctask.WithName("forgotten"))
+	ft := &fakeT{}
+	asynctasktest.VerifyNone(ft, &asynctasktest.VerifyNoneOptions{MaxWait: 20 * time.Millisecond})
+	assert.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], `"forgotten" Running`)
+
+	tsk.Cancel()
+	asynctasktest.VerifyNone(t, nil)
+
+	// finishing within MaxWait is fine.
+	asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	})
+	asynctasktest.VerifyNone(t, nil)
+}
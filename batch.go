@@ -0,0 +1,73 @@
+package asynctask
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// StartBatch run each function as a task over workers routines, rather than a routine each, and returns a handle for each, in order.
+// it's for huge bursts of tiny tasks, where scheduling a routine costs more than the task itself.
+// tasks are Queued until a worker picks them up, in order; context passed in may impact lifetime of every task,
+// and a task canceled or past its deadline while queued never runs.
+func StartBatch(ctx context.Context, fns []AsyncFunc, workers int, opts ...TaskOption) []*TaskStatus {
+	if workers > len(fns) {
+		workers = len(fns)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	items := make([]*poolItem, len(fns))
+	tasks := make([]*TaskStatus, len(fns))
+	for i, fn := range fns {
+		options := newTaskOptions(opts)
+		parentCtx, release := options.taskContext(ctx)
+		taskCtx, cancel := context.WithCancel(parentCtx)
+		record := newRunningTask(cancel, options)
+		record.state = StateQueued
+		record.trackDefault()
+		record.onDone(release)
+		deadline, hasDeadline := parentCtx.Deadline()
+		items[i] = &poolItem{
+			ctx:         taskCtx,
+			record:      record,
+			task:        fn,
+			deadline:    deadline,
+			hasDeadline: hasDeadline,
+			stopWatch:   failOnContextDone(parentCtx, taskCtx, record),
+		}
+		tasks[i] = record
+	}
+
+	// next is index of the last item picked up by a worker.
+	next := int64(-1)
+	for i := 0; i < workers; i++ {
+		go runBatch(items, &next)
+	}
+	return tasks
+}
+
+// runBatch runs items until none is left, each picked up once across workers.
+func runBatch(items []*poolItem, next *int64) {
+	exited := false
+	defer func() {
+		if !exited {
+			// task called runtime.Goexit on this worker, keep the worker count.
+			go runBatch(items, next)
+		}
+	}()
+
+	for {
+		i := atomic.AddInt64(next, 1)
+		if i >= int64(len(items)) {
+			exited = true
+			return
+		}
+		item := items[i]
+		if item.ready() {
+			runAndTrackTask(item.ctx, item.record, item.task)
+		}
+		// drop the reference, so finished functions can be collected while the batch runs.
+		items[i] = nil
+	}
+}
@@ -0,0 +1,87 @@
+package asynctask_test
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartBatch(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	running, maxRunning := int64(0), int64(0)
+	fns := make([]asynctask.AsyncFunc, 1000)
+	for i := range fns {
+		i := i
+		fns[i] = func(ctx context.Context) (interface{}, error) {
+			n := atomic.AddInt64(&running, 1)
+			for {
+				max := atomic.LoadInt64(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt64(&maxRunning, max, n) {
+					break
+				}
+			}
+			defer atomic.AddInt64(&running, -1)
+			if i == 10 {
+				panic("tiny task panic")
+			}
+			if i == 20 {
+				// worker routine exits, another one takes over.
+				runtime.Goexit()
+			}
+			return i * 2, nil
+		}
+	}
+
+	tasks := asynctask.StartBatch(ctx, fns, 4)
+	assert.Len(t, tasks, len(fns))
+	for i, tsk := range tasks {
+		result, err := tsk.Wait(ctx)
+		switch i {
+		case 10:
+			assert.Error(t, err)
+		case 20:
+			assert.Equal(t, asynctask.ErrGoexit, err)
+		default:
+			assert.NoError(t, err)
+			assert.Equal(t, i*2, result)
+		}
+	}
+	assert.LessOrEqual(t, maxRunning, int64(4))
+}
+
+func TestStartBatchCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	batchCtx, cancelBatch := context.WithCancel(ctx)
+	started := make(chan struct{})
+	fns := []asynctask.AsyncFunc{
+		func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		getCountingTask(1, time.Millisecond),
+		getCountingTask(1, time.Millisecond),
+	}
+	tasks := asynctask.StartBatch(batchCtx, fns, 1, asynctask.WithName("tiny"))
+	<-started
+	assert.Equal(t, asynctask.StateQueued, tasks[1].State())
+	assert.Equal(t, "tiny", tasks[1].Info().Name)
+
+	cancelBatch()
+	for _, tsk := range tasks {
+		_, err := tsk.Wait(ctx)
+		assert.Equal(t, context.Canceled, err)
+	}
+	assert.Equal(t, time.Time{}, tasks[2].Info().StartedAt, "queued task never ran")
+}
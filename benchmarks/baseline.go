@@ -0,0 +1,52 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// Baseline is the cost of one run of a scenario, as measured by Measure.
+type Baseline struct {
+	Scenario    string
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// Measure benchmarks the scenario, outside of go test, e.g. to record a baseline of a release.
+func Measure(scenario Scenario) (Baseline, error) {
+	var runErr error
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		ctx := context.Background()
+		for i := 0; i < b.N; i++ {
+			if err := scenario.Run(ctx, scenario.Size); err != nil {
+				runErr = err
+				return
+			}
+		}
+	})
+	if runErr != nil {
+		return Baseline{}, fmt.Errorf("%s: %w", scenario.Name, runErr)
+	}
+	return baselineOf(scenario.Name, result), nil
+}
+
+// Compare returns an error if current regressed from baseline by more than tolerance (0.1 is 10%),
+// in time, allocations or bytes, so a regression gate can live in code.
+func Compare(baseline, current Baseline, tolerance float64) error {
+	regressed := func(what string, before, after int64) error {
+		if float64(after) > float64(before)*(1+tolerance) {
+			return fmt.Errorf("%s regressed %s: %d -> %d", current.Scenario, what, before, after)
+		}
+		return nil
+	}
+	if err := regressed("ns/op", baseline.NsPerOp, current.NsPerOp); err != nil {
+		return err
+	}
+	if err := regressed("allocs/op", baseline.AllocsPerOp, current.AllocsPerOp); err != nil {
+		return err
+	}
+	return regressed("B/op", baseline.BytesPerOp, current.BytesPerOp)
+}
@@ -0,0 +1,125 @@
+// Package benchmarks provides realistic workloads of asynctask, and helpers to run them as benchmarks,
+// so downstream users can run go test -bench against each release and compare.
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Azure/go-asynctask"
+)
+
+// Scenario is a workload, Run executes it once at Size.
+type Scenario struct {
+	Name string
+	Size int
+	Run  func(ctx context.Context, size int) error
+}
+
+// WithSize returns a copy of the scenario at another size, e.g. smaller for a quick run.
+func (s Scenario) WithSize(size int) Scenario {
+	s.Size = size
+	return s
+}
+
+// Scenarios returns the standard workloads, at their default size:
+//   - short tasks: 1M tasks returning right away, started together then waited on
+//   - continuation chain: a chain of 10k ContinueWith, each passing the result on
+//   - concurrent waiters: 10k routines waiting on one task until it completes
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "ShortTasks", Size: 1000000, Run: ShortTasks},
+		{Name: "ContinuationChain", Size: 10000, Run: ContinuationChain},
+		{Name: "ConcurrentWaiters", Size: 10000, Run: ConcurrentWaiters},
+	}
+}
+
+// ShortTasks starts n tasks which return right away, and wait for all of them.
+func ShortTasks(ctx context.Context, n int) error {
+	tasks := make([]*asynctask.TaskStatus, n)
+	for i := range tasks {
+		tasks[i] = asynctask.Start(ctx, returnInput(i))
+	}
+	for _, tsk := range tasks {
+		if _, err := tsk.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContinuationChain builds a chain of depth tasks with ContinueWith, each incrementing the result of previous one,
+// and wait for the last one.
+func ContinuationChain(ctx context.Context, depth int) error {
+	tsk := asynctask.Start(ctx, returnInput(0))
+	for i := 1; i < depth; i++ {
+		tsk = tsk.ContinueWith(ctx, increment)
+	}
+	result, err := tsk.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	if result != depth-1 {
+		return fmt.Errorf("chain of %d returned %v", depth, result)
+	}
+	return nil
+}
+
+// ConcurrentWaiters start waiters routines waiting on a single task, which completes once all of them are waiting.
+func ConcurrentWaiters(ctx context.Context, waiters int) error {
+	release := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+
+	errs := make(chan error, waiters)
+	ready := sync.WaitGroup{}
+	ready.Add(waiters)
+	finished := sync.WaitGroup{}
+	finished.Add(waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			defer finished.Done()
+			ready.Done()
+			if _, err := tsk.Wait(ctx); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	ready.Wait()
+	close(release)
+	finished.Wait()
+
+	close(errs)
+	return <-errs
+}
+
+// Run runs each scenario as a sub benchmark of b, reporting allocations,
+// e.g. func BenchmarkAsynctask(b *testing.B) { benchmarks.Run(b, benchmarks.Scenarios()...) }
+func Run(b *testing.B, scenarios ...Scenario) {
+	for _, scenario := range scenarios {
+		scenario := scenario
+		b.Run(scenario.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if err := scenario.Run(ctx, scenario.Size); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func returnInput(i int) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		return i, nil
+	}
+}
+
+func increment(ctx context.Context, result interface{}) (interface{}, error) {
+	return result.(int) + 1, nil
+}
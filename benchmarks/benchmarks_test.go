@@ -0,0 +1,85 @@
+package benchmarks_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/Azure/go-asynctask/benchmarks"
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkScenarios(b *testing.B) {
+	benchmarks.Run(b, benchmarks.Scenarios()...)
+}
+
+func TestScenarios(t *testing.T) {
+	ctx := context.Background()
+	for _, scenario := range benchmarks.Scenarios() {
+		scenario = scenario.WithSize(100)
+		assert.NoError(t, scenario.Run(ctx, scenario.Size), scenario.Name)
+	}
+}
+
+func TestMeasureAndCompare(t *testing.T) {
+	scenario := benchmarks.Scenarios()[0].WithSize(10)
+	baseline, err := benchmarks.Measure(scenario)
+	assert.NoError(t, err)
+	assert.Equal(t, "ShortTasks", baseline.Scenario)
+	assert.Greater(t, baseline.NsPerOp, int64(0))
+	assert.Greater(t, baseline.AllocsPerOp, int64(0))
+
+	assert.NoError(t, benchmarks.Compare(baseline, baseline, 0))
+	slower := baseline
+	slower.NsPerOp = baseline.NsPerOp * 2
+	assert.Error(t, benchmarks.Compare(baseline, slower, 0.1))
+	assert.NoError(t, benchmarks.Compare(baseline, slower, 1.5))
+	moreAllocs := baseline
+	moreAllocs.AllocsPerOp = baseline.AllocsPerOp * 2
+	assert.EqualError(t, benchmarks.Compare(baseline, moreAllocs, 0.1),
+		"ShortTasks regressed allocs/op: "+strconv.FormatInt(baseline.AllocsPerOp, 10)+" -> "+strconv.FormatInt(moreAllocs.AllocsPerOp, 10))
+}
+
+// hot paths shouldn't allocate more than they do today.
+func TestHotPathAllocations(t *testing.T) {
+	ctx := context.Background()
+
+	completed := asynctask.NewCompletedTask()
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = completed.Wait(ctx)
+	})
+	assert.Equal(t, 0.0, allocs, "Wait on a completed task")
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _, _ = completed.TryResult()
+	})
+	assert.Equal(t, 0.0, allocs, "TryResult on a completed task")
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+			return nil, nil
+		}).Wait(ctx)
+	})
+	assert.LessOrEqual(t, allocs, 10.0, "Start and Wait")
+}
+
+func TestMeasureOverhead(t *testing.T) {
+	report := benchmarks.MeasureOverhead()
+	assert.Equal(t, "Start+Wait", report.Task.Scenario)
+	assert.Equal(t, "go+channel", report.Raw.Scenario)
+	assert.Greater(t, report.Task.NsPerOp, int64(0))
+	assert.Greater(t, report.Raw.NsPerOp, int64(0))
+	assert.Greater(t, report.Task.AllocsPerOp, report.Raw.AllocsPerOp)
+	assert.Greater(t, report.Ratio(), 0.0)
+
+	out := &strings.Builder{}
+	n, err := report.WriteTo(out)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	t.Log("\n" + out.String())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[1], "Start+Wait"))
+	assert.True(t, strings.HasPrefix(lines[3], "overhead"))
+}
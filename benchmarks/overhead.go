@@ -0,0 +1,76 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/Azure/go-asynctask"
+)
+
+// OverheadReport compares cost of Start and Wait on a function, to a raw go statement and a channel running it.
+type OverheadReport struct {
+	Task Baseline
+	Raw  Baseline
+}
+
+// MeasureOverhead benchmarks both ways of running an empty function, it takes a couple seconds.
+// it's opt-in, to decide whether an ultra hot path is worth wrapping in a task.
+func MeasureOverhead() OverheadReport {
+	ctx := context.Background()
+	noop := func(context.Context) (interface{}, error) { return nil, nil }
+
+	task := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = asynctask.Start(ctx, noop).Wait(ctx)
+		}
+	})
+	raw := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			done := make(chan struct{})
+			go func() {
+				_, _ = noop(ctx)
+				close(done)
+			}()
+			<-done
+		}
+	})
+
+	return OverheadReport{
+		Task: baselineOf("Start+Wait", task),
+		Raw:  baselineOf("go+channel", raw),
+	}
+}
+
+// Ratio returns how many times slower a task is than the raw way.
+func (r OverheadReport) Ratio() float64 {
+	if r.Raw.NsPerOp == 0 {
+		return 0
+	}
+	return float64(r.Task.NsPerOp) / float64(r.Raw.NsPerOp)
+}
+
+// WriteTo writes the report as a table, one line per way.
+func (r OverheadReport) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "%-12s %10s %10s %10s\n%s%s%-12s %9.1fx\n",
+		"", "ns/op", "allocs/op", "B/op",
+		baselineRow(r.Task), baselineRow(r.Raw),
+		"overhead", r.Ratio())
+	return int64(n), err
+}
+
+func baselineOf(name string, result testing.BenchmarkResult) Baseline {
+	return Baseline{
+		Scenario:    name,
+		NsPerOp:     result.NsPerOp(),
+		AllocsPerOp: result.AllocsPerOp(),
+		BytesPerOp:  result.AllocedBytesPerOp(),
+	}
+}
+
+func baselineRow(b Baseline) string {
+	return fmt.Sprintf("%-12s %10d %10d %10d\n", b.Scenario, b.NsPerOp, b.AllocsPerOp, b.BytesPerOp)
+}
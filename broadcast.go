@@ -0,0 +1,126 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBroadcastTriggered is returned from Broadcast.Trigger if the broadcast already has a producer.
+var ErrBroadcastTriggered = errors.New("broadcast already triggered")
+
+// Broadcast releases any number of subscriber tasks once its producer task finished,
+// e.g. everything in a service waiting for initialization to be done.
+// subscribers can register before the producer is known, or after it finished, in which case they get its outcome right away.
+type Broadcast struct {
+	mutex    sync.Mutex
+	producer *TaskStatus
+	// pending are launches of subscribers registered before the producer.
+	pending     []func()
+	subscribers int
+	// triggered is closed once the producer is set.
+	triggered chan struct{}
+}
+
+// NewBroadcast returns a broadcast without producer, see Trigger.
+func NewBroadcast() *Broadcast {
+	return &Broadcast{triggered: make(chan struct{})}
+}
+
+// StartBroadcast run the async function as producer of a new broadcast.
+func StartBroadcast(ctx context.Context, task AsyncFunc, opts ...TaskOption) *Broadcast {
+	b := NewBroadcast()
+	_ = b.Trigger(Start(ctx, task, opts...))
+	return b
+}
+
+// Trigger set the producer, whose completion releases subscribers, a broadcast has one producer only.
+func (b *Broadcast) Trigger(producer *TaskStatus) error {
+	b.mutex.Lock()
+	if b.producer != nil {
+		b.mutex.Unlock()
+		return ErrBroadcastTriggered
+	}
+	b.producer = producer
+	pending := b.pending
+	b.pending = nil
+	close(b.triggered)
+	b.mutex.Unlock()
+
+	for _, launch := range pending {
+		producer.onDone(launch)
+	}
+	return nil
+}
+
+// Producer returns the producer task, nil until triggered.
+func (b *Broadcast) Producer() *TaskStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.producer
+}
+
+// Subscribers returns number of subscribers registered so far.
+func (b *Broadcast) Subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.subscribers
+}
+
+// Subscribe run the function with result of the producer once it completed, task is in StateScheduled until then.
+// subscriber fails with error of the producer if it didn't complete, and is skipped if it got skipped.
+// context passed in may impact lifetime of the subscriber, canceled one never runs.
+func (b *Broadcast) Subscribe(ctx context.Context, next ContinueFunc, opts ...TaskOption) *TaskStatus {
+	options := newTaskOptions(opts)
+	ctx, release := options.taskContext(ctx)
+	taskCtx, cancel := context.WithCancel(ctx)
+	record := newRunningTask(cancel, options)
+	record.state = StateScheduled
+	record.trackDefault()
+	record.onDone(release)
+
+	stop := failOnContextDone(ctx, taskCtx, record)
+	launch := func(producer *TaskStatus) {
+		if !stop() || record.State().IsTerminalState() {
+			return
+		}
+		result, err := producer.outcome()
+		switch producer.State() {
+		case StateCompleted:
+			go runAndTrackTask(taskCtx, record, func(fCtx context.Context) (interface{}, error) {
+				return next(fCtx, result)
+			})
+		case StateSkipped:
+			record.finish(StateSkipped, nil, nil)
+		default:
+			record.finish(StateFailed, nil, err)
+		}
+	}
+
+	b.mutex.Lock()
+	b.subscribers++
+	producer := b.producer
+	if producer == nil {
+		b.pending = append(b.pending, func() {
+			launch(b.Producer())
+		})
+	}
+	b.mutex.Unlock()
+
+	if producer != nil {
+		producer.onDone(func() {
+			launch(producer)
+		})
+	}
+	return record
+}
+
+// Wait block until the broadcast is triggered and its producer finished, and returns outcome of the producer.
+func (b *Broadcast) Wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-b.triggered:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return b.Producer().waitFinished(ctx)
+}
@@ -0,0 +1,80 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcast(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	greet := func(ctx context.Context, config interface{}) (interface{}, error) {
+		return "hello " + config.(string), nil
+	}
+
+	// subscribe before the producer is known.
+	b := asynctask.NewBroadcast()
+	early := b.Subscribe(ctx, greet)
+	canceled := b.Subscribe(ctx, greet)
+	assert.Equal(t, asynctask.StateScheduled, early.State())
+	canceled.Cancel()
+
+	finish := make(chan struct{})
+	assert.NoError(t, b.Trigger(asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-finish
+		return "world", nil
+	})))
+	assert.Equal(t, asynctask.ErrBroadcastTriggered, b.Trigger(asynctask.NewCompletedTask()))
+	during := b.Subscribe(ctx, greet)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, asynctask.StateScheduled, during.State())
+
+	close(finish)
+	result, err := b.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", result)
+
+	// late subscriber get the cached outcome.
+	late := b.Subscribe(ctx, greet)
+	for _, tsk := range []*asynctask.TaskStatus{early, during, late} {
+		result, err := tsk.Wait(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", result)
+	}
+	_, err = canceled.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, 4, b.Subscribers())
+}
+
+func TestBroadcastProducerFailed(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errInit := errors.New("init failed")
+	b := asynctask.StartBroadcast(ctx, func(ctx context.Context) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return nil, errInit
+	})
+	run := func(ctx context.Context, _ interface{}) (interface{}, error) {
+		t.Error("subscriber ran after producer failed")
+		return nil, nil
+	}
+	early := b.Subscribe(ctx, run)
+	_, err := b.Wait(ctx)
+	assert.Equal(t, errInit, err)
+	late := b.Subscribe(ctx, run)
+
+	for _, tsk := range []*asynctask.TaskStatus{early, late} {
+		_, err := tsk.Wait(ctx)
+		assert.Equal(t, errInit, err)
+		assert.Equal(t, asynctask.StateFailed, tsk.State())
+	}
+}
@@ -0,0 +1,49 @@
+package asynctask
+
+import (
+	"context"
+	"time"
+)
+
+// RemainingBudget returns time left until deadline of ctx, ok is false if ctx has no deadline.
+// it's zero, not negative, past the deadline.
+func RemainingBudget(ctx context.Context) (remaining time.Duration, ok bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	remaining = time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// SplitBudget divides time left until deadline of ctx across sequential stages, in proportion of weights,
+// so the first stage can't consume the whole deadline, e.g. pass each share to WithTimeout of a stage.
+// negative weights count as zero, and all zero weights split evenly. it returns nil if ctx has no deadline.
+// call it again between stages to hand time a stage didn't use to the remaining ones.
+func SplitBudget(ctx context.Context, weights ...float64) []time.Duration {
+	remaining, ok := RemainingBudget(ctx)
+	if !ok {
+		return nil
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		if weight > 0 {
+			total += weight
+		}
+	}
+
+	shares := make([]time.Duration, len(weights))
+	for i, weight := range weights {
+		switch {
+		case total == 0:
+			shares[i] = remaining / time.Duration(len(weights))
+		case weight > 0:
+			shares[i] = time.Duration(float64(remaining) * weight / total)
+		}
+	}
+	return shares
+}
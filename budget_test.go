@@ -0,0 +1,61 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemainingBudget(t *testing.T) {
+	t.Parallel()
+
+	_, ok := asynctask.RemainingBudget(context.Background())
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	remaining, ok := asynctask.RemainingBudget(ctx)
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Second), float64(remaining), float64(100*time.Millisecond))
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	remaining, ok = asynctask.RemainingBudget(expired)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), remaining)
+}
+
+func TestSplitBudget(t *testing.T) {
+	t.Parallel()
+	tolerance := float64(50 * time.Millisecond)
+
+	assert.Nil(t, asynctask.SplitBudget(context.Background(), 1, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	shares := asynctask.SplitBudget(ctx, 1, 3)
+	assert.Len(t, shares, 2)
+	assert.InDelta(t, float64(250*time.Millisecond), float64(shares[0]), tolerance)
+	assert.InDelta(t, float64(750*time.Millisecond), float64(shares[1]), tolerance)
+
+	shares = asynctask.SplitBudget(ctx, 0, -1)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(shares[0]), tolerance)
+	assert.InDelta(t, float64(500*time.Millisecond), float64(shares[1]), tolerance)
+
+	shares = asynctask.SplitBudget(ctx, 2, 0)
+	assert.InDelta(t, float64(time.Second), float64(shares[0]), tolerance)
+	assert.Equal(t, time.Duration(0), shares[1])
+
+	// stage timed out at its share, the next one still has its own.
+	stage := asynctask.SplitBudget(ctx, 1, 9)
+	_, err := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, asynctask.WithTimeout(stage[0])).Wait(ctx)
+	assert.Equal(t, asynctask.ErrTimeout, err)
+	remaining, _ := asynctask.RemainingBudget(ctx)
+	assert.Greater(t, int64(remaining), int64(stage[1]/2))
+}
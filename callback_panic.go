@@ -0,0 +1,35 @@
+package asynctask
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// CallbackPanicHandler receives panics recovered from code run on behalf of a task outside its function:
+// OnDone callbacks, the EventSink, and the error classifier.
+type CallbackPanicHandler func(tsk *TaskStatus, err *PanicError)
+
+var globalCallbackPanicHandler atomic.Value
+
+type callbackPanicHandlerHolder struct {
+	handler CallbackPanicHandler
+}
+
+// SetCallbackPanicHandler sets the handler of panics recovered from callbacks, e.g. to log them, nil drops them.
+// a panicking callback doesn't affect state of the task, other callbacks of it, nor the routine which finished it.
+func SetCallbackPanicHandler(handler CallbackPanicHandler) {
+	globalCallbackPanicHandler.Store(callbackPanicHandlerHolder{handler: handler})
+}
+
+// safeCall runs callback of the task, recovering a panic and handing it to the CallbackPanicHandler.
+func (t *TaskStatus) safeCall(callback func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			holder, _ := globalCallbackPanicHandler.Load().(callbackPanicHandlerHolder)
+			if holder.handler != nil {
+				holder.handler(t, &PanicError{Value: r, stack: debug.Stack()})
+			}
+		}
+	}()
+	callback()
+}
@@ -0,0 +1,120 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type panickingSink struct {
+	asynctask.NopEventSink
+}
+
+func (panickingSink) OnFinish(*asynctask.TaskStatus, error) {
+	panic("sink panic")
+}
+
+// not parallel, it sets the global handler and sink.
+func TestCallbackPanic(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	mutex := sync.Mutex{}
+	var recovered []interface{}
+	asynctask.SetCallbackPanicHandler(func(tsk *asynctask.TaskStatus, err *asynctask.PanicError) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		recovered = append(recovered, err.Value)
+		assert.NotEmpty(t, err.Stack())
+	})
+	defer asynctask.SetCallbackPanicHandler(nil)
+	asynctask.SetEventSink(panickingSink{})
+	defer asynctask.SetEventSink(nil)
+
+	finish := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-finish
+		return "done", nil
+	})
+	var after []string
+	tsk.OnDone(func(interface{}, error) { after = append(after, "first") })
+	tsk.OnDone(func(interface{}, error) { panic("callback panic") })
+	tsk.OnDone(func(interface{}, error) { after = append(after, "last") })
+	next := tsk.ContinueWith(ctx, func(ctx context.Context, result interface{}) (interface{}, error) {
+		return result.(string) + " next", nil
+	})
+	close(finish)
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", result)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+	result, err = next.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "done next", result)
+	assert.Equal(t, []string{"first", "last"}, after)
+
+	// already finished, callback runs on the caller, which survives as well.
+	tsk.OnDone(func(interface{}, error) { panic("late callback panic") })
+
+	// canceling runs callbacks on the caller.
+	canceled := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	canceled.OnDone(func(interface{}, error) { panic("cancel callback panic") })
+	canceled.Cancel()
+	_, err = canceled.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Contains(t, recovered, "callback panic")
+	assert.Contains(t, recovered, "late callback panic")
+	assert.Contains(t, recovered, "cancel callback panic")
+	assert.Contains(t, recovered, "sink panic")
+}
+
+func TestPanicOutsideTaskFunction(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// continuation.
+	_, err := asynctask.NewCompletedTask().ContinueWith(ctx, func(ctx context.Context, result interface{}) (interface{}, error) {
+		panic("continuation panic")
+	}).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "continuation panic fails the continuation")
+
+	// middleware.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMiddleware(
+		func(info asynctask.TaskInfo, next asynctask.AsyncFunc) asynctask.AsyncFunc {
+			panic("middleware panic")
+		})).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "middleware panic fails the task")
+
+	// classifier, error is kept, unclassified.
+	errBoom := errors.New("boom")
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, errBoom
+	}, asynctask.WithErrorClassifier(func(err error) asynctask.ErrorClass {
+		panic("classifier panic")
+	}))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, errBoom, err)
+	assert.Equal(t, asynctask.ErrorClass(""), tsk.ErrorClass())
+
+	// graph condition fails the node.
+	run, err := asynctask.NewGraph().
+		AddNode("a", getCountingTask(1, time.Millisecond), nil).
+		AddNode("b", getCountingTask(1, time.Millisecond), []string{"a"}).
+		When("b", "a", func(interface{}) bool { panic("condition panic") }).
+		Run(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "condition panic fails the node")
+	assert.Equal(t, asynctask.StateFailed, run.Task("b").State())
+}
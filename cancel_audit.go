@@ -0,0 +1,114 @@
+package asynctask
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// CancelAuditReport is a canceled task whose function didn't return within grace, see SetCancelAudit.
+type CancelAuditReport struct {
+	Task       *TaskStatus
+	Info       TaskInfo
+	CanceledAt time.Time
+	// CancelStack is stack of the routine which canceled the task.
+	CancelStack []byte
+	// Stack is stack of the routine running the task function, once grace passed, i.e. where it ignores cancellation.
+	// empty if the routine couldn't be found.
+	Stack []byte
+}
+
+type cancelAuditHolder struct {
+	grace  time.Duration
+	report func(CancelAuditReport)
+}
+
+var globalCancelAudit atomic.Value
+
+// SetCancelAudit turns on a debug mode which reports canceled tasks whose function didn't return within grace,
+// to find functions ignoring ctx.Done(). report runs on its own routine; zero grace or nil report turns it off.
+// it only applies to functions starting to run afterwards, and reading stacks is costly, don't leave it on.
+func SetCancelAudit(grace time.Duration, report func(CancelAuditReport)) {
+	globalCancelAudit.Store(cancelAuditHolder{grace: grace, report: report})
+}
+
+func getCancelAudit() cancelAuditHolder {
+	holder, _ := globalCancelAudit.Load().(cancelAuditHolder)
+	return holder
+}
+
+// functionRun tracks the task function for cancel audit.
+type functionRun struct {
+	// returned is set once task function returned, panicked or exited.
+	returned int32
+	routine  uint64
+}
+
+// auditFunction record current routine runs the task function, if cancel audit is on.
+// returned should be called once function returned.
+func (t *TaskStatus) auditFunction() (returned func()) {
+	if audit := getCancelAudit(); audit.grace <= 0 || audit.report == nil {
+		return func() {}
+	}
+	run := &functionRun{routine: currentRoutine()}
+	t.mutex.Lock()
+	t.function = run
+	t.mutex.Unlock()
+	return func() {
+		atomic.StoreInt32(&run.returned, 1)
+	}
+}
+
+// auditCancel check on the function of a task being canceled once grace passed, if cancel audit is on.
+func (t *TaskStatus) auditCancel() {
+	audit := getCancelAudit()
+	t.mutex.Lock()
+	run := t.function
+	t.mutex.Unlock()
+	if audit.grace <= 0 || audit.report == nil || run == nil || atomic.LoadInt32(&run.returned) == 1 {
+		return
+	}
+
+	canceledAt := time.Now()
+	cancelStack := debug.Stack()
+	time.AfterFunc(audit.grace, func() {
+		if atomic.LoadInt32(&run.returned) == 1 {
+			return
+		}
+		audit.report(CancelAuditReport{
+			Task:        t,
+			Info:        t.Info(),
+			CanceledAt:  canceledAt,
+			CancelStack: cancelStack,
+			Stack:       routineStack(run.routine),
+		})
+	})
+}
+
+// currentRoutine returns id of current routine, parsed from its stack.
+func currentRoutine() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// routineStack returns stack of routine id, nil if it's gone.
+func routineStack(id uint64) []byte {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	header := []byte("goroutine " + strconv.FormatUint(id, 10) + " [")
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return stack
+		}
+	}
+	return nil
+}
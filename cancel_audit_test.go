@@ -0,0 +1,59 @@
+package asynctask_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func ignoreCancellation(release chan struct{}) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+}
+
+// not parallel, it turns on cancel audit for the process.
+func TestCancelAudit(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	reports := make(chan asynctask.CancelAuditReport, 10)
+	asynctask.SetCancelAudit(20*time.Millisecond, func(report asynctask.CancelAuditReport) {
+		reports <- report
+	})
+	defer asynctask.SetCancelAudit(0, nil)
+
+	release := make(chan struct{})
+	defer close(release)
+	stubborn := asynctask.Start(ctx, ignoreCancellation(release), asynctask.WithName("stubborn"))
+	polite := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, asynctask.WithName("polite"))
+	time.Sleep(5 * time.Millisecond)
+	stubborn.Cancel()
+	polite.Cancel()
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, stubborn, report.Task)
+		assert.Equal(t, "stubborn", report.Info.Name)
+		assert.Equal(t, asynctask.StateCanceled, report.Info.State)
+		assert.False(t, report.CanceledAt.IsZero())
+		assert.True(t, strings.Contains(string(report.CancelStack), "TestCancelAudit"), string(report.CancelStack))
+		assert.True(t, strings.Contains(string(report.Stack), "ignoreCancellation"), string(report.Stack))
+	case <-ctx.Done():
+		t.Fatal("stubborn task not reported")
+	}
+
+	select {
+	case report := <-reports:
+		t.Fatalf("unexpected report of %q", report.Info.Name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,141 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// CancelToken is a one-shot cancellation signal which can be linked to many tasks.
+// Trigger it once, every linked task get canceled with the same cause.
+type CancelToken struct {
+	mutex     sync.Mutex
+	done      chan struct{}
+	cause     error
+	callbacks []func(error)
+}
+
+// NewCancelToken returns a token that is not yet canceled.
+func NewCancelToken() *CancelToken {
+	return &CancelToken{
+		done: make(chan struct{}),
+	}
+}
+
+// NewCancelTokenFromContext returns a token which get canceled when ctx is done,
+// with ctx.Err() as the cause.
+func NewCancelTokenFromContext(ctx context.Context) *CancelToken {
+	token := NewCancelToken()
+	go func() {
+		select {
+		case <-ctx.Done():
+			token.Cancel(ctx.Err())
+		case <-token.done:
+		}
+	}()
+
+	return token
+}
+
+// LinkTokens returns a new token which get canceled when any of the tokens passed in is canceled,
+// the cause from the first canceled token is carried over.
+func LinkTokens(tokens ...*CancelToken) *CancelToken {
+	linked := NewCancelToken()
+	for _, token := range tokens {
+		token.onCancel(linked.Cancel)
+	}
+
+	return linked
+}
+
+// Cancel trigger the token, only first call take effect.
+// cause will be reported by Err(), and wrapped in error of linked tasks; nil cause means ErrCanceled.
+func (ct *CancelToken) Cancel(cause error) {
+	if cause == nil {
+		cause = ErrCanceled
+	}
+
+	ct.mutex.Lock()
+	if ct.cause != nil {
+		ct.mutex.Unlock()
+		return
+	}
+	ct.cause = cause
+	callbacks := ct.callbacks
+	ct.callbacks = nil
+	close(ct.done)
+	ct.mutex.Unlock()
+
+	// run callbacks outside of lock, linked tokens may call back into us.
+	for _, callback := range callbacks {
+		callback(cause)
+	}
+}
+
+// Done returns a channel that is closed when the token is canceled.
+func (ct *CancelToken) Done() <-chan struct{} {
+	return ct.done
+}
+
+// Err returns nil if the token is not yet canceled, otherwise the cause it canceled with.
+func (ct *CancelToken) Err() error {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+	return ct.cause
+}
+
+// IsCanceled tells whether the token was triggered.
+func (ct *CancelToken) IsCanceled() bool {
+	return ct.Err() != nil
+}
+
+// Link registers tasks to be canceled when the token is triggered.
+// tasks get canceled immediately if the token was already triggered.
+func (ct *CancelToken) Link(tasks ...*TaskStatus) {
+	for _, tsk := range tasks {
+		tsk := tsk
+		ct.onCancel(func(cause error) {
+			tsk.cancelWithError(newCanceledError(cause))
+		})
+	}
+}
+
+// onCancel registers callback to run on cancellation, or run it right away if already canceled.
+func (ct *CancelToken) onCancel(callback func(error)) {
+	ct.mutex.Lock()
+	if ct.cause == nil {
+		ct.callbacks = append(ct.callbacks, callback)
+		ct.mutex.Unlock()
+		return
+	}
+	cause := ct.cause
+	ct.mutex.Unlock()
+
+	callback(cause)
+}
+
+// canceledError is ErrCanceled carrying the cause of cancellation.
+type canceledError struct {
+	cause error
+}
+
+func newCanceledError(cause error) error {
+	if cause == nil || cause == ErrCanceled {
+		return ErrCanceled
+	}
+	return &canceledError{cause: cause}
+}
+
+func (e *canceledError) Error() string {
+	return ErrCanceled.Error() + ": " + e.cause.Error()
+}
+
+// Is makes errors.Is(err, ErrCanceled) and errors.Is(err, context.Canceled) work.
+func (e *canceledError) Is(target error) bool {
+	return errors.Is(ErrCanceled, target)
+}
+
+// Unwrap returns the cause of cancellation.
+func (e *canceledError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,84 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelToken(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	token := asynctask.NewCancelToken()
+	t1 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	t2 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	token.Link(t1, t2)
+	assert.False(t, token.IsCanceled())
+	assert.NoError(t, token.Err())
+
+	shutdown := errors.New("shutting down")
+	token.Cancel(shutdown)
+	// second cancel doesn't change the cause
+	token.Cancel(errors.New("another cause"))
+	assert.Equal(t, shutdown, token.Err())
+
+	for _, tsk := range []*asynctask.TaskStatus{t1, t2} {
+		rawResult, err := tsk.Wait(ctx)
+		assert.Nil(t, rawResult)
+		assert.Equal(t, asynctask.StateCanceled, tsk.State())
+		assert.True(t, errors.Is(err, asynctask.ErrCanceled), "expecting ErrCanceled")
+		assert.True(t, errors.Is(err, shutdown), "expecting cause to be wrapped")
+	}
+
+	// link after cancel, task get canceled right away.
+	t3 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	token.Link(t3)
+	assert.Equal(t, asynctask.StateCanceled, t3.State())
+}
+
+func TestCancelTokenNilCause(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	token := asynctask.NewCancelToken()
+	t1 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	token.Link(t1)
+	token.Cancel(nil)
+
+	_, err := t1.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, asynctask.ErrCanceled, token.Err())
+}
+
+func TestLinkTokens(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	reqCtx, reqCancel := context.WithCancel(ctx)
+	stopSignal := asynctask.NewCancelToken()
+	linked := asynctask.LinkTokens(stopSignal, asynctask.NewCancelTokenFromContext(reqCtx))
+
+	t1 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	linked.Link(t1)
+
+	reqCancel()
+	select {
+	case <-linked.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "linked token should be canceled with request context")
+	}
+	assert.True(t, errors.Is(linked.Err(), context.Canceled))
+	assert.False(t, stopSignal.IsCanceled(), "source token shouldn't be affected")
+
+	_, err := t1.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrCanceled))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
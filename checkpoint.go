@@ -0,0 +1,40 @@
+package asynctask
+
+import (
+	"context"
+)
+
+// CheckpointSaveFunc persists state of a task, so it can resume after restart.
+type CheckpointSaveFunc func(ctx context.Context, state []byte) error
+
+// CheckpointLoadFunc returns last state persisted by CheckpointSaveFunc, nil if there is none.
+type CheckpointLoadFunc func(ctx context.Context) ([]byte, error)
+
+// WithCheckpointer let the task function persist progress with SaveCheckpoint,
+// and resume from it with LoadCheckpoint when the task is started again (e.g. after process restart).
+func WithCheckpointer(save CheckpointSaveFunc, load CheckpointLoadFunc) TaskOption {
+	return func(o *taskOptions) {
+		o.checkpointSave = save
+		o.checkpointLoad = load
+	}
+}
+
+// SaveCheckpoint persists state through the checkpointer of the task running with ctx.
+// it's no-op if the task has no checkpointer.
+func SaveCheckpoint(ctx context.Context, state []byte) error {
+	tsk := taskFromContext(ctx)
+	if tsk == nil || tsk.options.checkpointSave == nil {
+		return nil
+	}
+	return tsk.options.checkpointSave(ctx, state)
+}
+
+// LoadCheckpoint returns last state saved through the checkpointer of the task running with ctx,
+// nil if nothing was saved, or the task has no checkpointer; the task should start over in that case.
+func LoadCheckpoint(ctx context.Context) ([]byte, error) {
+	tsk := taskFromContext(ctx)
+	if tsk == nil || tsk.options.checkpointLoad == nil {
+		return nil, nil
+	}
+	return tsk.options.checkpointLoad(ctx)
+}
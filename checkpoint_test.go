@@ -0,0 +1,78 @@
+package asynctask_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCheckpoint mimic a persistent store that outlives the task.
+type memoryCheckpoint struct {
+	mutex sync.Mutex
+	state []byte
+}
+
+func (m *memoryCheckpoint) save(ctx context.Context, state []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.state = state
+	return nil
+}
+
+func (m *memoryCheckpoint) load(ctx context.Context) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.state, nil
+}
+
+// getResumableCountingTask counts to countTo, saving count after each step, and stop after stopAfter steps.
+func getResumableCountingTask(countTo, stopAfter int) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		state, err := asynctask.LoadCheckpoint(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		count := 0
+		if state != nil {
+			count, _ = strconv.Atoi(string(state))
+		}
+
+		for steps := 0; count < countTo && steps < stopAfter; steps++ {
+			count++
+			if err := asynctask.SaveCheckpoint(ctx, []byte(strconv.Itoa(count))); err != nil {
+				return nil, err
+			}
+		}
+		return count, nil
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	store := &memoryCheckpoint{}
+	tsk := asynctask.Start(ctx, getResumableCountingTask(10, 4), asynctask.WithCheckpointer(store.save, store.load))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result)
+
+	// start again, resume from 4
+	tsk = asynctask.Start(ctx, getResumableCountingTask(10, 4), asynctask.WithCheckpointer(store.save, store.load))
+	result, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, result)
+
+	// without checkpointer, always start over
+	tsk = asynctask.Start(ctx, getResumableCountingTask(10, 4))
+	result, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result)
+}
@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ChunkedParallel split items into chunks of chunkSize, and run fn on each chunk, at most concurrency chunks at a time.
+// every chunk is processed even if some fail, errors (annotated with chunk index) are returned as an AggregateError.
+// chunkSize and concurrency less than 1 are treated as 1.
+func ChunkedParallel[T any](ctx context.Context, items []T, chunkSize, concurrency int, fn func(context.Context, []T) error) error {
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	chunks := make(chan int)
+	go func() {
+		defer close(chunks)
+		for start := 0; start < len(items); start += chunkSize {
+			select {
+			case chunks <- start:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	mutex := sync.Mutex{}
+	var errs []error
+	workers := make([]AsyncFunc, concurrency)
+	for i := range workers {
+		workers[i] = func(fCtx context.Context) (interface{}, error) {
+			for start := range chunks {
+				end := start + chunkSize
+				if end > len(items) {
+					end = len(items)
+				}
+
+				chunk := items[start:end:end]
+				_, err := Start(fCtx, func(cCtx context.Context) (interface{}, error) {
+					return nil, fn(cCtx, chunk)
+				}).Wait(fCtx)
+				if err != nil {
+					mutex.Lock()
+					errs = append(errs, fmt.Errorf("chunk %d: %w", start/chunkSize, err))
+					mutex.Unlock()
+				}
+			}
+			return nil, nil
+		}
+	}
+
+	// workers stop soon after ctx is done: no more chunks, and waits on a chunk are interrupted.
+	// so waiting without ctx is safe, and no worker is left touching errs.
+	if err := StartGroup(ctx, workers...).Wait(context.Background(), nil); err != nil {
+		errs = append(errs, err)
+	}
+	if ctx.Err() != nil && len(errs) == 0 {
+		errs = append(errs, ctx.Err())
+	}
+	return aggregate(errs)
+}
@@ -0,0 +1,75 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedParallel(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	items := make([]int, 250)
+	for i := range items {
+		items[i] = i
+	}
+
+	mutex := sync.Mutex{}
+	var chunkSizes []int
+	sum := 0
+	var running, maxRunning int32
+	err := asynctask.ChunkedParallel(ctx, items, 100, 2, func(ctx context.Context, chunk []int) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if current > maxRunning {
+			maxRunning = current
+		}
+		chunkSizes = append(chunkSizes, len(chunk))
+		for _, item := range chunk {
+			sum += item
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{100, 100, 50}, chunkSizes)
+	assert.Equal(t, 249*250/2, sum)
+	assert.True(t, maxRunning <= 2, "concurrency should be bounded")
+}
+
+func TestChunkedParallelErrors(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var processed int32
+	err := asynctask.ChunkedParallel(ctx, []string{"a", "b", "c", "d", "e"}, 2, 3, func(ctx context.Context, chunk []string) error {
+		atomic.AddInt32(&processed, 1)
+		if chunk[0] == "a" || chunk[0] == "e" {
+			return errors.New("write failed")
+		}
+		return nil
+	})
+
+	var aggregated *asynctask.AggregateError
+	assert.True(t, errors.As(err, &aggregated))
+	assert.Len(t, aggregated.Errors, 2)
+	assert.ElementsMatch(t, []string{"chunk 0: write failed", "chunk 2: write failed"},
+		[]string{aggregated.Errors[0].Error(), aggregated.Errors[1].Error()})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&processed), "all chunks should be processed")
+}
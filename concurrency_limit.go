@@ -0,0 +1,114 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrLimitMismatch is returned for a task whose concurrency limit differs from the limit of the same name in use.
+var ErrLimitMismatch = errors.New("concurrency limit mismatch")
+
+// limiterMap holds limiters by name.
+type limiterMap struct {
+	sync.Mutex
+	limiters map[string]*namedLimit
+}
+
+// namedLimit is a limiter with number of tasks holding or waiting for it, it's removed once there is none.
+type namedLimit struct {
+	slots chan struct{}
+	users int
+}
+
+// concurrencyLimits are process wide limiters by name, see WithConcurrencyLimit.
+var concurrencyLimits = &limiterMap{limiters: map[string]*namedLimit{}}
+
+// WithConcurrencyLimit allows at most n tasks with same limit name to run at the same time, across the process,
+// no matter how they're started (Start, Pool, ContinueWith etc).
+// task waits for its turn before the function runs, a pool task waits holding its worker.
+// limit of a name is set by the task using it first, a task with another n fails with ErrLimitMismatch while the limit is in use,
+// i.e. held or waited for by some task.
+func WithConcurrencyLimit(name string, n int) TaskOption {
+	return func(o *taskOptions) {
+		if n < 1 {
+			n = 1
+		}
+		o.limitName = name
+		o.limit = n
+	}
+}
+
+// acquireLimit blocks until the task can run under its concurrency limits: the named mutex (see WithMutex),
+// the named limit and the group one (see WithGroupConcurrency). returned release should be called once it's done.
+func (t *TaskStatus) acquireLimit(ctx context.Context) (release func(), err error) {
+	releaseMutex, err := t.acquireMutex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var limiters []chan struct{}
+	releaseName := func() {}
+	if name := t.options.limitName; name != "" {
+		limiter, err := concurrencyLimits.use(name, t.options.limit)
+		if err != nil {
+			releaseMutex()
+			return nil, err
+		}
+		limiters = append(limiters, limiter)
+		releaseName = func() { concurrencyLimits.leave(name) }
+	}
+	if t.options.groupLimiter != nil {
+		limiters = append(limiters, t.options.groupLimiter)
+	}
+
+	release = func() {
+		for _, limiter := range limiters {
+			<-limiter
+		}
+		releaseName()
+		releaseMutex()
+	}
+	for i, limiter := range limiters {
+		select {
+		case limiter <- struct{}{}:
+		case <-ctx.Done():
+			// give back the ones we got.
+			for _, acquired := range limiters[:i] {
+				<-acquired
+			}
+			releaseName()
+			releaseMutex()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// use returns limiter of name, created with capacity n if it's not in use, leave should be called once done with it.
+func (limits *limiterMap) use(name string, n int) (chan struct{}, error) {
+	limits.Lock()
+	defer limits.Unlock()
+	limit, ok := limits.limiters[name]
+	if !ok {
+		limit = &namedLimit{slots: make(chan struct{}, n)}
+		limits.limiters[name] = limit
+	}
+	if cap(limit.slots) != n {
+		return nil, fmt.Errorf("%w: %q is %d, not %d", ErrLimitMismatch, name, cap(limit.slots), n)
+	}
+	limit.users++
+	return limit.slots, nil
+}
+
+// leave drops use of limiter of name, and removes it once nobody uses it.
+func (limits *limiterMap) leave(name string) {
+	limits.Lock()
+	defer limits.Unlock()
+	limit := limits.limiters[name]
+	limit.users--
+	if limit.users == 0 {
+		delete(limits.limiters, name)
+	}
+}
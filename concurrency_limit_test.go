@@ -0,0 +1,92 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var running, peak int32
+	fullSync := func(context.Context) (interface{}, error) {
+		now := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	}
+
+	// limit holds across tasks started in different ways.
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 4})
+	defer pool.Close()
+	limit := asynctask.WithConcurrencyLimit("TestWithConcurrencyLimit/full-sync", 2)
+	var tasks []*asynctask.TaskStatus
+	for i := 0; i < 4; i++ {
+		tasks = append(tasks, asynctask.Start(ctx, fullSync, limit), pool.Submit(ctx, fullSync, limit))
+	}
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tasks...))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+
+	// waiting for a turn can be canceled.
+	release := make(chan struct{})
+	blocking := asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}, asynctask.WithConcurrencyLimit("TestWithConcurrencyLimit/single", 1))
+	time.Sleep(5 * time.Millisecond)
+	var ran int32
+	waiting := asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&ran, 1)
+		return nil, nil
+	}, asynctask.WithConcurrencyLimit("TestWithConcurrencyLimit/single", 1))
+	time.Sleep(5 * time.Millisecond)
+	waiting.Cancel()
+	close(release)
+	_, err := blocking.Wait(ctx)
+	assert.NoError(t, err)
+	_, err = waiting.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&ran))
+}
+
+func TestConcurrencyLimitMismatch(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	const name = "TestConcurrencyLimitMismatch/deploy"
+	release := make(chan struct{})
+	holding := asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}, asynctask.WithConcurrencyLimit(name, 1))
+	time.Sleep(5 * time.Millisecond)
+
+	// another n fails while the limit is in use.
+	mismatched := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithConcurrencyLimit(name, 2))
+	_, err := mismatched.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrLimitMismatch), "expected ErrLimitMismatch")
+	assert.Equal(t, asynctask.StateFailed, mismatched.State())
+
+	close(release)
+	_, err = holding.Wait(ctx)
+	assert.NoError(t, err)
+
+	// limit is dropped once idle, so the next task sets it anew.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithConcurrencyLimit(name, 2)).Wait(ctx)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,43 @@
+//go:build go1.20
+// +build go1.20
+
+package asynctask
+
+import (
+	"context"
+	"errors"
+)
+
+// withCause wraps err with context.Cause(ctx), if err is the error of ctx being done, and ctx was done with a cause,
+// so Wait tells deadline, operator abort and shutdown apart, while errors.Is(err, context.Canceled) keeps working.
+func withCause(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if err == nil || ctxErr == nil || !errors.Is(err, ctxErr) {
+		return err
+	}
+	cause := context.Cause(ctx)
+	if cause == nil || cause == ctxErr {
+		return err
+	}
+	return &causeError{err: err, cause: cause}
+}
+
+// causeError is error of a done context, carrying the cause context was done with.
+type causeError struct {
+	err   error
+	cause error
+}
+
+func (e *causeError) Error() string {
+	return e.err.Error() + ": " + e.cause.Error()
+}
+
+// Is makes errors.Is(err, context.Canceled) work.
+func (e *causeError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// Unwrap returns the cause.
+func (e *causeError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,11 @@
+//go:build !go1.20
+// +build !go1.20
+
+package asynctask
+
+import "context"
+
+// withCause returns err as is, context.Cause is not available before go1.20.
+func withCause(ctx context.Context, err error) error {
+	return err
+}
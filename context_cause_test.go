@@ -0,0 +1,85 @@
+//go:build go1.20
+// +build go1.20
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var errShutdown = errors.New("shutdown")
+
+func TestContextCause(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// parent context canceled with a cause, while task running.
+	parentCtx, cancelParent := context.WithCancelCause(ctx)
+	tsk := asynctask.Start(parentCtx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cancelParent(errShutdown)
+	_, err := tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+	assert.True(t, errors.Is(err, context.Canceled), "expecting context.Canceled")
+	assert.Equal(t, "context canceled: shutdown", err.Error())
+
+	// and while queued.
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+	parentCtx, cancelParent = context.WithCancelCause(ctx)
+	tsk = pool.Submit(parentCtx, getCountingTask(1, time.Millisecond))
+	cancelParent(errShutdown)
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+	release()
+
+	// and while a continuation waits on its task.
+	parentCtx, cancelParent = context.WithCancelCause(ctx)
+	tsk = asynctask.Start(ctx, getCountingTask(5, 10*time.Millisecond)).ContinueWith(parentCtx, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	cancelParent(errShutdown)
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+	assert.True(t, errors.Is(err, context.Canceled), "expecting context.Canceled")
+
+	// deadline with a cause
+	parentCtx, cancelDeadline := context.WithTimeoutCause(ctx, 10*time.Millisecond, errShutdown)
+	defer cancelDeadline()
+	_, err = asynctask.Start(parentCtx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}).Wait(ctx)
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting context.DeadlineExceeded")
+
+	// plain cancel keeps the error as is.
+	parentCtx, cancelPlain := context.WithCancel(ctx)
+	tsk = asynctask.Start(parentCtx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cancelPlain()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	// operator abort through the handle.
+	tsk = asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	tsk.CancelWithCause(errShutdown)
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrCanceled), "expecting ErrCanceled")
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+}
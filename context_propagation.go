@@ -0,0 +1,95 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithDetachedContext runs the task with values of the context passed in, but not its cancellation or deadline.
+// e.g. submit a background task from a request handler, keeping trace id and auth, but not the request deadline.
+// the task can still be canceled through its handle.
+func WithDetachedContext() TaskOption {
+	return func(o *taskOptions) {
+		o.detached = true
+		o.detachedDeadline = false
+		o.valueKeys = nil
+	}
+}
+
+// WithContextValues is WithDetachedContext, but only values of the listed keys are carried into the task.
+func WithContextValues(keys ...interface{}) TaskOption {
+	return func(o *taskOptions) {
+		o.detached = true
+		o.detachedDeadline = false
+		o.valueKeys = append([]interface{}{}, keys...)
+	}
+}
+
+// WithDetachedDeadline runs the task with values and cancellation of the context passed in, but not its deadline.
+// e.g. a task which has to outlast a short request deadline, but should stop if the request is canceled.
+// once the deadline passed, the task is on its own, only its handle can cancel it.
+func WithDetachedDeadline() TaskOption {
+	return func(o *taskOptions) {
+		o.detached = false
+		o.detachedDeadline = true
+		o.valueKeys = nil
+	}
+}
+
+// taskContext returns the context a task should run with, derived from ctx as options say.
+// release should be called once the task finished, so the context stops watching ctx, it's nil if there is nothing to release.
+func (o *taskOptions) taskContext(ctx context.Context) (taskCtx context.Context, release func()) {
+	switch {
+	case o.detached:
+		return &detachedContext{values: ctx, keys: o.valueKeys}, nil
+	case o.detachedDeadline:
+		return withoutDeadline(ctx)
+	default:
+		return ctx, nil
+	}
+}
+
+// withoutDeadline returns a context with values of ctx, which is canceled if ctx is canceled, but not when its deadline passed.
+// release stops watching ctx, e.g. a long lived server context, it's nil if ctx is already canceled.
+func withoutDeadline(ctx context.Context) (detachedCtx context.Context, release func()) {
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return ctx, nil
+	}
+
+	detached, cancel := context.WithCancel(&detachedContext{values: ctx})
+	stop := afterFunc(ctx, func() {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			cancel()
+		}
+	})
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
+
+// detachedContext is never canceled and has no deadline, values come from the context it's detached from.
+type detachedContext struct {
+	values context.Context
+	// keys limits values to these keys, nil means all.
+	keys []interface{}
+}
+
+func (c *detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (c *detachedContext) Done() <-chan struct{} { return nil }
+
+func (c *detachedContext) Err() error { return nil }
+
+func (c *detachedContext) Value(key interface{}) interface{} {
+	if c.keys == nil {
+		return c.values.Value(key)
+	}
+	for _, allowed := range c.keys {
+		if allowed == key {
+			return c.values.Value(key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build go1.21
+// +build go1.21
+
+package asynctask_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// watchedContext counts functions registered to run once it's done, which are still registered.
+type watchedContext struct {
+	context.Context
+	watchers int32
+}
+
+// Value hides values of Context, so contexts derived from it can't find its parent and watch it through AfterFunc.
+func (c *watchedContext) Value(interface{}) interface{} {
+	return nil
+}
+
+// AfterFunc is used by context.AfterFunc, and contexts derived from it.
+func (c *watchedContext) AfterFunc(f func()) func() bool {
+	atomic.AddInt32(&c.watchers, 1)
+	stop := context.AfterFunc(c.Context, f)
+	return func() bool {
+		stopped := stop()
+		if stopped {
+			atomic.AddInt32(&c.watchers, -1)
+		}
+		return stopped
+	}
+}
+
+func TestDetachedDeadlineReleasesContext(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// a long lived server context isn't watched by finished tasks.
+	serverCtx := &watchedContext{Context: ctx}
+	for i := 0; i < 3; i++ {
+		_, err := asynctask.Start(serverCtx, func(context.Context) (interface{}, error) {
+			return nil, nil
+		}, asynctask.WithDetachedDeadline()).Wait(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&serverCtx.watchers) == 0
+	}, time.Second, time.Millisecond)
+}
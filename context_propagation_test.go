@@ -0,0 +1,86 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type propagationKey string
+
+const (
+	traceIDKey propagationKey = "traceID"
+	authKey    propagationKey = "auth"
+)
+
+func getContextReadingTask() asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		_, hasDeadline := ctx.Deadline()
+		return []interface{}{ctx.Value(traceIDKey), ctx.Value(authKey), hasDeadline}, ctx.Err()
+	}
+}
+
+func TestPoolContextPropagation(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 3})
+	defer pool.Close()
+
+	reqCtx, cancelReq := context.WithTimeout(context.WithValue(context.WithValue(ctx, traceIDKey, "trace-1"), authKey, "token"), 5*time.Millisecond)
+	defer cancelReq()
+
+	inherited := pool.Submit(reqCtx, getContextReadingTask())
+	detached := pool.Submit(reqCtx, getContextReadingTask(), asynctask.WithDetachedContext())
+	whitelisted := pool.Submit(reqCtx, getContextReadingTask(), asynctask.WithContextValues(traceIDKey))
+
+	// request deadline passes while inherited task running
+	_, err := inherited.Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	result, err := detached.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"trace-1", "token", false}, result)
+
+	result, err = whitelisted.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"trace-1", nil, false}, result)
+
+	// detached task can still be canceled through its handle
+	tsk := asynctask.Start(reqCtx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, asynctask.WithDetachedContext())
+	tsk.Cancel()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+}
+
+func TestDetachedDeadline(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// outlast the request deadline, keeping values.
+	reqCtx, cancelReq := context.WithTimeout(context.WithValue(context.WithValue(ctx, traceIDKey, "trace-1"), authKey, "token"), 5*time.Millisecond)
+	defer cancelReq()
+	tsk := asynctask.Start(reqCtx, getContextReadingTask(), asynctask.WithDetachedDeadline())
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"trace-1", "token", false}, result)
+
+	// but stop when request is canceled.
+	reqCtx, cancelReq = context.WithTimeout(ctx, time.Second)
+	tsk = asynctask.Start(reqCtx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, asynctask.WithDetachedDeadline())
+	cancelReq()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -7,12 +7,32 @@ type ContinueFunc func(context.Context, interface{}) (interface{}, error)
 
 // ContinueWith start the function when current task is done.
 // result from previous task will be passed in, if no error.
-func (tsk *TaskStatus) ContinueWith(ctx context.Context, next ContinueFunc) *TaskStatus {
-	return Start(ctx, func(fCtx context.Context) (interface{}, error) {
-		result, err := tsk.Wait(fCtx)
-		if err != nil {
-			return nil, err
+// no routine is held while waiting on current task, the function get its own routine once current task is done.
+func (tsk *TaskStatus) ContinueWith(ctx context.Context, next ContinueFunc, opts ...TaskOption) *TaskStatus {
+	options := newTaskOptions(opts)
+	parentCtx, release := options.taskContext(ctx)
+	ctx, cancel := context.WithCancel(parentCtx)
+	record := newRunningTask(cancel, options)
+	record.trackDefault()
+	record.onDone(release)
+
+	// context done before current task is done, fail like a task which never started.
+	stop := failOnContextDone(parentCtx, ctx, record)
+
+	tsk.onDone(func() {
+		if !stop() {
+			// continuation already finished through context cancellation.
+			return
 		}
-		return next(fCtx, result)
+
+		go runAndTrackTask(ctx, record, func(fCtx context.Context) (interface{}, error) {
+			result, err := tsk.outcome()
+			if err != nil {
+				return nil, err
+			}
+			return next(fCtx, result)
+		})
 	})
+
+	return record
 }
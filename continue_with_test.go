@@ -75,3 +75,38 @@ func TestContinueWithFailureCase(t *testing.T) {
 	assert.Equal(t, asynctask.StateFailed, t3.State(), "Task3 should fail since preceeding task failed")
 	assert.Equal(t, "devide by 0", err.Error())
 }
+
+func TestContinueWithContextCanceled(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+	t1 := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+
+	continueCtx, cancelContinue := context.WithCancel(ctx)
+	t2 := t1.ContinueWith(continueCtx, func(fCtx context.Context, input interface{}) (interface{}, error) {
+		assert.Fail(t, "continuation shouldn't run after its context canceled")
+		return nil, nil
+	})
+	cancelContinue()
+
+	_, err := t2.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, asynctask.StateFailed, t2.State())
+
+	// t1 is not affected, and nothing happen after it's done.
+	result, err := t1.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, result)
+	assert.Equal(t, asynctask.StateFailed, t2.State())
+}
+
+func TestContinueWithCompletedTask(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+	t2 := asynctask.NewCompletedTask().ContinueWith(ctx, func(fCtx context.Context, input interface{}) (interface{}, error) {
+		return "next", nil
+	})
+
+	result, err := t2.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", result)
+}
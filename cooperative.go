@@ -0,0 +1,37 @@
+package asynctask
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+)
+
+// Checkpoint returns nil if ctx isn't done yet, sprinkle it in tight loops of task functions so they stop soon after cancel.
+// it's also where a paused task waits to be resumed, see Pause.
+// if ctx belongs to a task which already finished, the task error is returned (ErrCanceled, ErrTimeout, cancel cause etc.),
+// ctx.Err() otherwise. calls are counted in TaskInfo.Checkpoints, as a liveness signal.
+func Checkpoint(ctx context.Context) error {
+	tsk := taskFromContext(ctx)
+	if tsk != nil {
+		atomic.AddUint64(&tsk.checkpoints, 1)
+		tsk.holdIfPaused(ctx)
+	}
+
+	err := ctx.Err()
+	if err == nil || tsk == nil {
+		return err
+	}
+
+	tsk.mutex.Lock()
+	defer tsk.mutex.Unlock()
+	if tsk.state.IsTerminalState() && tsk.err != nil {
+		return tsk.err
+	}
+	return err
+}
+
+// Yield is Checkpoint which also yields the processor, letting other routines run, for CPU bound loops.
+func Yield(ctx context.Context) error {
+	runtime.Gosched()
+	return Checkpoint(ctx)
+}
@@ -0,0 +1,63 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// busy loop which only stops at checkpoints.
+	loops := make(chan int, 1)
+	tsk := asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		for i := 0; ; i++ {
+			if i == 100 {
+				loops <- i
+			}
+			if err := asynctask.Yield(fCtx); err != nil {
+				return i, err
+			}
+		}
+	})
+
+	<-loops
+	assert.True(t, tsk.Info().Checkpoints >= 100)
+	tsk.Cancel()
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	// cause of the cancel shows up at checkpoint.
+	token := asynctask.NewCancelToken()
+	errShutdown := errors.New("shutting down")
+	checkpointErr := make(chan error, 1)
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		<-fCtx.Done()
+		for {
+			// context is done a moment before task records the cancel.
+			if err := asynctask.Checkpoint(fCtx); err != context.Canceled {
+				checkpointErr <- err
+				return nil, err
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	token.Link(tsk)
+	token.Cancel(errShutdown)
+	err = <-checkpointErr
+	assert.True(t, errors.Is(err, asynctask.ErrCanceled), "expecting ErrCanceled")
+	assert.True(t, errors.Is(err, errShutdown), "expecting cause")
+
+	// plain context.
+	plainCtx, cancelPlain := context.WithCancel(ctx)
+	assert.NoError(t, asynctask.Checkpoint(plainCtx))
+	cancelPlain()
+	assert.Equal(t, context.Canceled, asynctask.Checkpoint(plainCtx))
+}
@@ -0,0 +1,40 @@
+package asynctask
+
+import "runtime"
+
+// WithCPUTime records CPU time of the task function, beyond wall-clock duration, see TaskInfo.CPUTime.
+// task routine is locked to its OS thread while the function runs, to measure the thread,
+// so routines spawned by the function are not counted. it's only supported on linux, CPUTime stays zero elsewhere.
+func WithCPUTime() TaskOption {
+	return func(o *taskOptions) {
+		o.measureCPU = true
+	}
+}
+
+// measureCPU lock the task routine to its thread and take a sample before the task function runs,
+// stop should be called on the same routine once it returned, to record CPU time, calling it again is no-op.
+func (t *TaskStatus) measureCPU() (stop func()) {
+	if !t.options.measureCPU {
+		return func() {}
+	}
+
+	runtime.LockOSThread()
+	before, ok := threadCPUTime()
+	if !ok {
+		runtime.UnlockOSThread()
+		return func() {}
+	}
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		after, _ := threadCPUTime()
+		runtime.UnlockOSThread()
+
+		t.mutex.Lock()
+		t.cpuTime = after - before
+		t.mutex.Unlock()
+	}
+}
@@ -0,0 +1,18 @@
+package asynctask
+
+import (
+	"syscall"
+	"time"
+)
+
+// rusageThread is RUSAGE_THREAD, missing from syscall.
+const rusageThread = 1
+
+// threadCPUTime returns user and system CPU time of the calling thread.
+func threadCPUTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(rusageThread, &usage); err != nil {
+		return 0, false
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
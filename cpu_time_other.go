@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package asynctask
+
+import "time"
+
+// threadCPUTime is not supported off linux.
+func threadCPUTime() (time.Duration, bool) {
+	return 0, false
+}
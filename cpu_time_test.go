@@ -0,0 +1,64 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCPUTime(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" {
+		t.Skip("CPU time is only supported on linux")
+	}
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	busy := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		n := 0
+		for start := time.Now(); time.Since(start) < 50*time.Millisecond; {
+			n++
+		}
+		return n, nil
+	}, asynctask.WithCPUTime())
+	idle := asynctask.Start(ctx, getCountingTask(5, 10*time.Millisecond), asynctask.WithCPUTime())
+	_, err := busy.Wait(ctx)
+	assert.NoError(t, err)
+	_, err = idle.Wait(ctx)
+	assert.NoError(t, err)
+
+	busyInfo, idleInfo := busy.Info(), idle.Info()
+	assert.Greater(t, int64(busyInfo.CPUTime), int64(0))
+	assert.LessOrEqual(t, int64(busyInfo.CPUTime), int64(busyInfo.Duration()))
+	assert.Less(t, int64(idleInfo.CPUTime), int64(idleInfo.Duration()/2))
+
+	// not opted in.
+	tsk := asynctask.Start(ctx, getCountingTask(1, time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), tsk.Info().CPUTime)
+}
+
+func TestWithCPUTimePanicUnlocksPoolWorker(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+
+	_, err := pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		panic("boom")
+	}, asynctask.WithCPUTime()).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic))
+
+	// worker is still usable.
+	result, err := pool.Submit(ctx, getCountingTask(1, time.Millisecond)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+}
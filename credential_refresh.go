@@ -0,0 +1,23 @@
+package asynctask
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithCredentialRefresh wraps fn so an auth-expiry error, as told by expired, refresh credentials through refresh and run fn once more.
+// error from refresh fails the function, and a second expiry is returned as is.
+// refresh may be invoked by many tasks sharing the credential at once, dedupe in it (e.g. with sync.Mutex and a token expiry check) if needed.
+func WithCredentialRefresh(fn AsyncFunc, expired func(error) bool, refresh func(context.Context) error) AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		result, err := fn(ctx)
+		if err == nil || !expired(err) {
+			return result, err
+		}
+
+		if refreshErr := refresh(ctx); refreshErr != nil {
+			return nil, fmt.Errorf("credential refresh failed: %w", refreshErr)
+		}
+		return fn(ctx)
+	}
+}
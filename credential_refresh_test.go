@@ -0,0 +1,80 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTokenExpired = errors.New("401 token expired")
+
+func isTokenExpired(err error) bool {
+	return errors.Is(err, errTokenExpired)
+}
+
+func TestWithCredentialRefresh(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var token int32
+	call := func(ctx context.Context) (interface{}, error) {
+		if atomic.LoadInt32(&token) == 0 {
+			return nil, errTokenExpired
+		}
+		return "data", nil
+	}
+	var refreshes int32
+	refresh := func(ctx context.Context) error {
+		atomic.AddInt32(&refreshes, 1)
+		atomic.StoreInt32(&token, 1)
+		return nil
+	}
+
+	tsk := asynctask.Start(ctx, asynctask.WithCredentialRefresh(call, isTokenExpired, refresh))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", result)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+
+	// valid token, no refresh.
+	tsk = asynctask.Start(ctx, asynctask.WithCredentialRefresh(call, isTokenExpired, refresh))
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+
+	// other errors are not refreshed.
+	tsk = asynctask.Start(ctx, asynctask.WithCredentialRefresh(getErrorTask("dummy error", 0), isTokenExpired, refresh))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, "dummy error", err.Error())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+}
+
+func TestWithCredentialRefreshFailed(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var calls int32
+	alwaysExpired := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errTokenExpired
+	}
+
+	// retried only once.
+	tsk := asynctask.Start(ctx, asynctask.WithCredentialRefresh(alwaysExpired, isTokenExpired, func(context.Context) error { return nil }))
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, errTokenExpired, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	errIdentity := errors.New("identity provider unavailable")
+	tsk = asynctask.Start(ctx, asynctask.WithCredentialRefresh(alwaysExpired, isTokenExpired, func(context.Context) error { return errIdentity }))
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, errIdentity), "expecting refresh error")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+)
+
+// DoWithCancel run a blocking call which doesn't take a context (legacy drivers etc.), and return its result,
+// or ctx.Err() as soon as ctx is done, so a task using it can still be canceled.
+// onCancel (can be nil) is invoked once ctx is done before the call returns, use it to interrupt the call, like closing the connection.
+// the call keeps running on its own routine until it returns, its result is abandoned.
+func DoWithCancel[T any](ctx context.Context, blockingCall func() (T, error), onCancel func()) (T, error) {
+	type outcome struct {
+		value T
+		err   error
+	}
+
+	// buffered, so an abandoned call can still deliver and exit.
+	done := make(chan outcome, 1)
+	go func() {
+		var o outcome
+		defer func() {
+			if r := recover(); r != nil {
+				o.err = newPanicError(r)
+			}
+			done <- o
+		}()
+		o.value, o.err = blockingCall()
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		if onCancel != nil {
+			onCancel()
+		}
+		var zero T
+		return zero, ctx.Err()
+	}
+}
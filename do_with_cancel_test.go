@@ -0,0 +1,57 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoWithCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	result, err := asynctask.DoWithCancel(ctx, func() (int, error) {
+		return 42, nil
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, result)
+
+	_, err = asynctask.DoWithCancel(ctx, func() (int, error) {
+		panic("yo")
+	}, nil)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+}
+
+func TestDoWithCancelCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// legacy call blocks until interrupted.
+	interrupt := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		return asynctask.DoWithCancel(fCtx, func() (int, error) {
+			<-interrupt
+			return 0, errors.New("connection closed")
+		}, func() { close(interrupt) })
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	tsk.Cancel()
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	select {
+	case <-interrupt:
+	case <-time.After(time.Second):
+		t.Fatal("onCancel not invoked")
+	}
+}
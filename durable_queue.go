@@ -0,0 +1,174 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrQueueEmpty is returned by DurableQueue.Claim if nothing is ready to be claimed.
+var ErrQueueEmpty = errors.New("queue empty")
+
+// ErrLeaseLost is returned if a lease expired and the task was claimed by someone else.
+var ErrLeaseLost = errors.New("lease lost")
+
+// ErrDeadLetterRequired fails a durable worker started with MaxAttempts but no DeadLetter,
+// which would otherwise drop tasks exceeding their attempts without a trace.
+var ErrDeadLetterRequired = errors.New("durable worker with MaxAttempts requires DeadLetter")
+
+// TaskDescriptor describes a unit of work which can be persisted, and executed later by any worker.
+type TaskDescriptor struct {
+	// ID is assigned by the queue on Enqueue.
+	ID string
+	// Name tells the handler what kind of work it is.
+	Name string
+	// IdempotencyKey identifies the logical job, optional.
+	// enqueue with a key of a task still in the queue, or completed recently, returns that task instead of adding a duplicate.
+	IdempotencyKey string
+	// Payload is the input of the work, opaque to the queue.
+	Payload []byte
+	// Labels are labels of a task submitted to a pool (see WithLabel), for its admission controller, see PoolOptions.
+	Labels map[string]string
+	// Attempt counts claims of the task, starting from 1 on first claim.
+	Attempt int
+	// Errors has errors of previous failed attempts, oldest first.
+	Errors     []string
+	EnqueuedAt time.Time
+}
+
+// Lease is a claim on a task, only the lease holder can complete or release the task, until the lease expires.
+type Lease struct {
+	Descriptor TaskDescriptor
+	// Token identifies the claim, an expired lease can be claimed again with a new token.
+	Token     string
+	Worker    string
+	ExpiresAt time.Time
+}
+
+// DurableQueue stores task descriptors so submitted work survive process restarts, and hand them out to workers with leases.
+// tasks are delivered at-least-once: task from an expired lease is claimable again, handlers should be idempotent.
+type DurableQueue interface {
+	// Enqueue stores the task, and returns it with ID assigned.
+	// if desc has IdempotencyKey of a task still in the queue, or completed within retention of the queue,
+	// that task is returned and nothing is added.
+	Enqueue(ctx context.Context, desc TaskDescriptor) (TaskDescriptor, error)
+	// Claim leases the oldest ready task to worker, ErrQueueEmpty if there is none.
+	Claim(ctx context.Context, worker string, leaseDuration time.Duration) (*Lease, error)
+	// Complete removes a task which finished successfully.
+	Complete(ctx context.Context, lease *Lease) error
+	// Release returns a failed task to the queue to be claimed again, taskErr is added to its Errors.
+	Release(ctx context.Context, lease *Lease, taskErr error) error
+}
+
+// DurableHandler runs the work described by desc.
+type DurableHandler func(ctx context.Context, desc TaskDescriptor) error
+
+// DeadLetterFunc receives a task which used up its attempts,
+// desc.Attempt is number of attempts made, and desc.Errors has error of every attempt.
+type DeadLetterFunc func(ctx context.Context, desc TaskDescriptor) error
+
+// DurableWorkerOptions defines options for StartDurableWorker function
+type DurableWorkerOptions struct {
+	// Worker identifies the worker in leases, default to "worker".
+	Worker string
+	// LeaseDuration is how long a claimed task is reserved, default to 1 minute.
+	// handler taking longer than this may see the task run again by another worker.
+	LeaseDuration time.Duration
+	// PollInterval is the wait between claims when queue is empty, default to 1 second.
+	PollInterval time.Duration
+	// MaxAttempts is the retry budget of a task, zero means no limit.
+	// task failed on its last attempt is handed to DeadLetter and removed from the queue.
+	MaxAttempts int
+	// DeadLetter receives tasks exceeding MaxAttempts, task remains in the queue if it returns error.
+	// it's required with MaxAttempts, the worker fails with ErrDeadLetterRequired otherwise.
+	DeadLetter DeadLetterFunc
+}
+
+const (
+	defaultDurableWorker       = "worker"
+	defaultDurableLease        = time.Minute
+	defaultDurablePollInterval = time.Second
+)
+
+// StartDurableWorker run a task which claims tasks from queue and runs them with handler one at a time, until canceled.
+// succeeded task is completed, failed one is released back to the queue.
+// start multiple workers on same queue for concurrency.
+func StartDurableWorker(ctx context.Context, queue DurableQueue, handler DurableHandler, options *DurableWorkerOptions) *TaskStatus {
+	resolved := DurableWorkerOptions{
+		Worker:        defaultDurableWorker,
+		LeaseDuration: defaultDurableLease,
+		PollInterval:  defaultDurablePollInterval,
+	}
+	if options != nil {
+		if options.Worker != "" {
+			resolved.Worker = options.Worker
+		}
+		if options.LeaseDuration > 0 {
+			resolved.LeaseDuration = options.LeaseDuration
+		}
+		if options.PollInterval > 0 {
+			resolved.PollInterval = options.PollInterval
+		}
+		resolved.MaxAttempts = options.MaxAttempts
+		resolved.DeadLetter = options.DeadLetter
+	}
+
+	return Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		if resolved.MaxAttempts > 0 && resolved.DeadLetter == nil {
+			return nil, ErrDeadLetterRequired
+		}
+		for {
+			lease, err := queue.Claim(fCtx, resolved.Worker, resolved.LeaseDuration)
+			if errors.Is(err, ErrQueueEmpty) {
+				select {
+				case <-time.After(resolved.PollInterval):
+					continue
+				case <-fCtx.Done():
+					return nil, fCtx.Err()
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if err := runDurableTask(fCtx, queue, handler, lease, &resolved); err != nil {
+				return nil, err
+			}
+		}
+	}, WithName(resolved.Worker))
+}
+
+// runDurableTask runs the leased task and settle it with the queue, only error from queue is returned.
+func runDurableTask(ctx context.Context, queue DurableQueue, handler DurableHandler, lease *Lease, options *DurableWorkerOptions) error {
+	tsk := Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		return nil, handler(fCtx, lease.Descriptor)
+	}, WithName(lease.Descriptor.Name))
+
+	_, taskErr := tsk.waitFinished(ctx)
+	if ctx.Err() != nil {
+		// worker is stopping, leave the task to be claimed again after lease expires.
+		tsk.Cancel()
+		return ctx.Err()
+	}
+
+	if taskErr == nil {
+		return ignoreLeaseLost(queue.Complete(ctx, lease))
+	}
+
+	if options.MaxAttempts > 0 && lease.Descriptor.Attempt >= options.MaxAttempts {
+		desc := lease.Descriptor
+		desc.Errors = append(desc.Errors, taskErr.Error())
+		if options.DeadLetter(ctx, desc) == nil {
+			return ignoreLeaseLost(queue.Complete(ctx, lease))
+		}
+	}
+	return ignoreLeaseLost(queue.Release(ctx, lease, taskErr))
+}
+
+// ignoreLeaseLost swallow ErrLeaseLost, the task is someone else's now.
+func ignoreLeaseLost(err error) error {
+	if errors.Is(err, ErrLeaseLost) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurableWorker(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	queue := asynctask.NewMemoryQueue()
+	for _, tenant := range []string{"a", "b", "c"} {
+		_, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte(tenant)})
+		assert.NoError(t, err)
+	}
+
+	mutex := sync.Mutex{}
+	var handled []string
+	done := make(chan struct{})
+	worker := asynctask.StartDurableWorker(ctx, queue, func(ctx context.Context, desc asynctask.TaskDescriptor) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		// b fails on first attempt, and get retried.
+		if string(desc.Payload) == "b" && desc.Attempt == 1 {
+			return errors.New("throttled")
+		}
+		handled = append(handled, string(desc.Payload))
+		if len(handled) == 3 {
+			close(done)
+		}
+		return nil
+	}, &asynctask.DurableWorkerOptions{PollInterval: time.Millisecond})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "worker should handle all tasks")
+	}
+	assert.Eventually(t, func() bool { return queue.Len() == 0 }, time.Second, time.Millisecond)
+	worker.Cancel()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"a", "c", "b"}, handled)
+	assert.Equal(t, asynctask.StateCanceled, worker.State())
+}
+
+func TestDurableWorkerDeadLetter(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	queue := asynctask.NewMemoryQueue()
+	_, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("broken")})
+	assert.NoError(t, err)
+
+	deadLetters := make(chan asynctask.TaskDescriptor, 1)
+	worker := asynctask.StartDurableWorker(ctx, queue, func(ctx context.Context, desc asynctask.TaskDescriptor) error {
+		return errors.New("bad payload")
+	}, &asynctask.DurableWorkerOptions{
+		PollInterval: time.Millisecond,
+		MaxAttempts:  3,
+		DeadLetter: func(ctx context.Context, desc asynctask.TaskDescriptor) error {
+			deadLetters <- desc
+			return nil
+		},
+	})
+	defer worker.Cancel()
+
+	select {
+	case desc := <-deadLetters:
+		assert.Equal(t, "broken", string(desc.Payload))
+		assert.Equal(t, 3, desc.Attempt)
+		assert.Equal(t, []string{"bad payload", "bad payload", "bad payload"}, desc.Errors)
+	case <-time.After(time.Second):
+		assert.Fail(t, "task should be dead-lettered")
+	}
+	assert.Eventually(t, func() bool { return queue.Len() == 0 }, time.Second, time.Millisecond)
+
+	// tasks are never dropped silently.
+	_, err = asynctask.StartDurableWorker(ctx, queue, func(ctx context.Context, desc asynctask.TaskDescriptor) error {
+		return nil
+	}, &asynctask.DurableWorkerOptions{MaxAttempts: 3}).Wait(ctx)
+	assert.Equal(t, asynctask.ErrDeadLetterRequired, err)
+}
@@ -0,0 +1,61 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorClass tells what kind of failure a task error is, see WithErrorClassifier.
+type ErrorClass string
+
+// ClassTransient indicate the error may go away if tried again.
+const ClassTransient ErrorClass = "Transient"
+
+// ClassPermanent indicate the error will happen again, no point retrying.
+const ClassPermanent ErrorClass = "Permanent"
+
+// ClassThrottled indicate the dependency asked us to slow down, worth retrying after a backoff.
+const ClassThrottled ErrorClass = "Throttled"
+
+// ClassCanceled indicate the task (or its context) got canceled.
+const ClassCanceled ErrorClass = "Canceled"
+
+// IsRetryable tells whether an error of the class is worth retrying.
+func (c ErrorClass) IsRetryable() bool {
+	return c == ClassTransient || c == ClassThrottled
+}
+
+// ErrorClassifier tells class of a non-nil task error.
+type ErrorClassifier func(err error) ErrorClass
+
+// WithErrorClassifier classify errors of the task,
+// class is exposed through ErrorClass and TaskInfo, and with WithRetry only retryable classes are retried (unless RetryPolicy.ShouldRetry is set).
+func WithErrorClassifier(classifier ErrorClassifier) TaskOption {
+	return func(o *taskOptions) {
+		o.classifier = classifier
+	}
+}
+
+// ErrorClass returns class of the task error, empty if task has no error, or no classifier and isn't canceled.
+func (t *TaskStatus) ErrorClass() ErrorClass {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.errorClass
+}
+
+// classify returns class of err, using classifier of the task if any.
+func (t *TaskStatus) classify(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ""
+	case t.options.classifier != nil:
+		// a panicking classifier leaves the error unclassified.
+		var class ErrorClass
+		t.safeCall(func() { class = t.options.classifier(err) })
+		return class
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,109 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var errThrottled = errors.New("429 too many requests")
+var errNotFound = errors.New("404 not found")
+
+// getFailingTask fails the first n calls with err, and succeed afterwards.
+func getFailingTask(n int32, err error) asynctask.AsyncFunc {
+	var calls int32
+	return func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) <= n {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+func classifyHTTPError(err error) asynctask.ErrorClass {
+	switch {
+	case errors.Is(err, errThrottled):
+		return asynctask.ClassThrottled
+	case errors.Is(err, errNotFound):
+		return asynctask.ClassPermanent
+	case errors.Is(err, asynctask.ErrCanceled):
+		return asynctask.ClassCanceled
+	default:
+		return asynctask.ClassTransient
+	}
+}
+
+func TestErrorClassifier(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	policy := asynctask.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+
+	// throttled is retried.
+	tsk := asynctask.Start(ctx, getFailingTask(1, errThrottled), asynctask.WithRetry(policy), asynctask.WithErrorClassifier(classifyHTTPError))
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.ErrorClass(""), tsk.ErrorClass())
+
+	// permanent is not.
+	tsk = asynctask.Start(ctx, getFailingTask(1, errNotFound), asynctask.WithRetry(policy), asynctask.WithErrorClassifier(classifyHTTPError))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, asynctask.ClassPermanent, tsk.ErrorClass())
+	info := tsk.Info()
+	assert.Equal(t, 1, info.Attempt)
+	assert.Equal(t, asynctask.ClassPermanent, info.ErrorClass)
+
+	tsk = asynctask.Start(ctx, getCountingTask(10, 100*time.Millisecond), asynctask.WithErrorClassifier(classifyHTTPError))
+	tsk.Cancel()
+	assert.Equal(t, asynctask.ClassCanceled, tsk.ErrorClass())
+}
+
+func TestErrorClassDefault(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getErrorTask("dummy error", time.Millisecond))
+	_, err := tsk.Wait(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, asynctask.ErrorClass(""), tsk.ErrorClass(), "unclassified without classifier")
+
+	tsk = asynctask.Start(ctx, getCountingTask(10, 100*time.Millisecond))
+	tsk.Cancel()
+	assert.Equal(t, asynctask.ClassCanceled, tsk.ErrorClass())
+
+	assert.True(t, asynctask.ClassTransient.IsRetryable())
+	assert.True(t, asynctask.ClassThrottled.IsRetryable())
+	assert.False(t, asynctask.ClassPermanent.IsRetryable())
+	assert.False(t, asynctask.ClassCanceled.IsRetryable())
+}
+
+func TestPoolStatsFailures(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	tasks := []*asynctask.TaskStatus{
+		pool.Submit(ctx, getFailingTask(1, errThrottled), asynctask.WithErrorClassifier(classifyHTTPError)),
+		pool.Submit(ctx, getFailingTask(1, errNotFound), asynctask.WithErrorClassifier(classifyHTTPError)),
+		pool.Submit(ctx, getFailingTask(1, errNotFound), asynctask.WithErrorClassifier(classifyHTTPError)),
+		pool.Submit(ctx, getCountingTask(3, time.Millisecond), asynctask.WithErrorClassifier(classifyHTTPError)),
+	}
+	pool.Close()
+	for _, tsk := range tasks {
+		_, _ = tsk.Wait(ctx)
+	}
+
+	assert.Equal(t, map[asynctask.ErrorClass]uint64{
+		asynctask.ClassThrottled: 1,
+		asynctask.ClassPermanent: 2,
+	}, pool.Stats().Failures)
+}
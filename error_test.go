@@ -3,6 +3,7 @@ package asynctask_test
 import (
 	"context"
 	"errors"
+	"runtime"
 	"testing"
 	"time"
 
@@ -44,6 +45,8 @@ func TestTimeoutCase(t *testing.T) {
 	tsk := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
 	_, err := tsk.WaitWithTimeout(ctx, 300*time.Millisecond)
 	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+	assert.True(t, errors.Is(err, asynctask.ErrWaitTimeout), "expecting ErrWaitTimeout")
+	assert.Equal(t, asynctask.StateRunning, tsk.State(), "task should keep running")
 
 	// the last Wait error should affect running task
 	// I can continue wait with longer time
@@ -67,6 +70,33 @@ func TestPanicCase(t *testing.T) {
 	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
 }
 
+func TestGoexitCase(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		runtime.Goexit()
+		return nil, nil
+	})
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrGoexit, err)
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// worker keeps serving after a task exited its routine.
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	_, err = pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		runtime.Goexit()
+		return nil, nil
+	}).Wait(ctx)
+	assert.Equal(t, asynctask.ErrGoexit, err)
+	result, err := pool.Submit(ctx, getCountingTask(3, time.Millisecond)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+	assert.Equal(t, 0, pool.Stats().Running)
+}
+
 func TestErrorCase(t *testing.T) {
 	t.Parallel()
 	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
@@ -125,3 +155,54 @@ func TestStructErrorCase(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, result, "Done")
 }
+
+func TestWaitTimeoutFromCaller(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	defer tsk.Cancel()
+
+	// caller's own deadline is not a wait timeout
+	callerCtx, cancelCaller := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelCaller()
+	_, err := tsk.WaitWithTimeout(callerCtx, time.Second)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, errors.Is(err, asynctask.ErrWaitTimeout))
+}
+
+func TestDeadlineExceededErrorCase(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, context.DeadlineExceeded
+	})
+
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+}
+
+func TestStandardLibraryErrorCheck(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	tsk.Cancel()
+	_, err := tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrCanceled), "expecting ErrCanceled")
+	assert.True(t, errors.Is(err, context.Canceled), "expecting context.Canceled")
+
+	tsk = asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	tsk.CancelWithCause(errors.New("operator abort"))
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), "expecting context.Canceled")
+
+	_, err = asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond), asynctask.WithTimeout(time.Millisecond)).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrTimeout), "expecting ErrTimeout")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting context.DeadlineExceeded")
+}
@@ -0,0 +1,75 @@
+package asynctask
+
+import (
+	"sync/atomic"
+)
+
+// EventSink receives state transitions of tasks, e.g. to feed audit or telemetry pipelines.
+// methods are invoked synchronously on the routine making the transition, keep them short.
+type EventSink interface {
+	// OnStart is invoked when task function start running.
+	OnStart(tsk *TaskStatus)
+	// OnFinish is invoked when task Completed or Skipped (err is nil), or Failed.
+	OnFinish(tsk *TaskStatus, err error)
+	// OnRetry is invoked when a retrying task failed an attempt and is about to try again.
+	OnRetry(tsk *TaskStatus, attempt int, err error)
+	// OnCancel is invoked when task got canceled.
+	OnCancel(tsk *TaskStatus, err error)
+}
+
+// NopEventSink ignores all events, embed it to implement only events you care about.
+type NopEventSink struct{}
+
+// OnStart implements EventSink.
+func (NopEventSink) OnStart(*TaskStatus) {}
+
+// OnFinish implements EventSink.
+func (NopEventSink) OnFinish(*TaskStatus, error) {}
+
+// OnRetry implements EventSink.
+func (NopEventSink) OnRetry(*TaskStatus, int, error) {}
+
+// OnCancel implements EventSink.
+func (NopEventSink) OnCancel(*TaskStatus, error) {}
+
+// eventSinkHolder keeps atomic.Value storing same concrete type.
+type eventSinkHolder struct {
+	sink EventSink
+}
+
+var globalEventSink atomic.Value
+
+// SetEventSink sets the sink receiving events of tasks started afterwards, nil to stop sending events.
+// a pool can have its own, see PoolOptions.EventSink.
+func SetEventSink(sink EventSink) {
+	globalEventSink.Store(eventSinkHolder{sink: sink})
+}
+
+// getEventSink returns the global sink, nil if not set.
+func getEventSink() EventSink {
+	holder, _ := globalEventSink.Load().(eventSinkHolder)
+	return holder.sink
+}
+
+func (t *TaskStatus) emitStart() {
+	if t.sink != nil {
+		t.safeCall(func() { t.sink.OnStart(t) })
+	}
+}
+
+func (t *TaskStatus) emitRetry(attempt int, err error) {
+	if t.sink != nil {
+		t.safeCall(func() { t.sink.OnRetry(t, attempt, err) })
+	}
+}
+
+func (t *TaskStatus) emitFinish(state State, err error) {
+	if t.sink == nil {
+		return
+	}
+	if state == StateCanceled {
+		t.safeCall(func() { t.sink.OnCancel(t, err) })
+		return
+	}
+	t.safeCall(func() { t.sink.OnFinish(t, err) })
+}
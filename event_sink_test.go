@@ -0,0 +1,130 @@
+package asynctask_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	asynctask.NopEventSink
+	mutex  sync.Mutex
+	events map[*asynctask.TaskStatus][]string
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{events: map[*asynctask.TaskStatus][]string{}}
+}
+
+func (s *recordingSink) record(tsk *asynctask.TaskStatus, event string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events[tsk] = append(s.events[tsk], event)
+}
+
+func (s *recordingSink) eventsOf(tsk *asynctask.TaskStatus) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.events[tsk]
+}
+
+func (s *recordingSink) OnStart(tsk *asynctask.TaskStatus) {
+	s.record(tsk, "start")
+}
+
+func (s *recordingSink) OnFinish(tsk *asynctask.TaskStatus, err error) {
+	if err != nil {
+		s.record(tsk, "failed")
+		return
+	}
+	s.record(tsk, "completed")
+}
+
+func (s *recordingSink) OnRetry(tsk *asynctask.TaskStatus, attempt int, err error) {
+	s.record(tsk, "retry")
+}
+
+func (s *recordingSink) OnCancel(tsk *asynctask.TaskStatus, err error) {
+	s.record(tsk, "canceled")
+}
+
+// not parallel, it changes global sink.
+func TestEventSink(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	sink := newRecordingSink()
+	asynctask.SetEventSink(sink)
+	defer asynctask.SetEventSink(nil)
+
+	completed := asynctask.Start(ctx, getCountingTask(2, time.Millisecond))
+	failed := asynctask.Start(ctx, getErrorTask("expected error", time.Millisecond))
+	var calls int32
+	retried := asynctask.Start(ctx, getFlakyTask(2, &calls), asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 2}))
+	canceled := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	canceled.Cancel()
+
+	err := asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, completed, failed, retried, canceled)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"start", "completed"}, sink.eventsOf(completed))
+	assert.Equal(t, []string{"start", "failed"}, sink.eventsOf(failed))
+	assert.Equal(t, []string{"start", "canceled"}, sink.eventsOf(canceled))
+	assert.Equal(t, []string{"start", "retry", "completed"}, sink.eventsOf(retried))
+
+	// tasks started after sink removed don't report.
+	asynctask.SetEventSink(nil)
+	tsk := asynctask.Start(ctx, getCountingTask(2, time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, sink.eventsOf(tsk))
+}
+
+func TestPoolEventSink(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	sink := newRecordingSink()
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, EventSink: sink})
+	defer pool.Close()
+
+	pooled := pool.Submit(ctx, getCountingTask(2, time.Millisecond))
+	started := asynctask.Start(ctx, getCountingTask(2, time.Millisecond))
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, pooled, started))
+
+	assert.Eventually(t, func() bool {
+		return len(sink.eventsOf(pooled)) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"start", "completed"}, sink.eventsOf(pooled))
+	assert.Empty(t, sink.eventsOf(started))
+}
+
+// statsSink reads pool stats on finish.
+type statsSink struct {
+	asynctask.NopEventSink
+	pool *asynctask.Pool
+}
+
+func (s *statsSink) OnFinish(*asynctask.TaskStatus, error) {
+	s.pool.Stats()
+}
+
+func TestPoolEventSinkClosedPool(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	sink := &statsSink{}
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, EventSink: sink})
+	sink.pool = pool
+	pool.Close()
+
+	// sink using the pool doesn't deadlock Submit on a closed pool.
+	_, err := pool.Submit(ctx, getCountingTask(1, time.Millisecond)).Wait(ctx)
+	assert.Equal(t, asynctask.ErrPoolClosed, err)
+}
@@ -0,0 +1,146 @@
+package asynctask
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WatchOptions defines options for WatchFiles function
+type WatchOptions struct {
+	// Interval between scans of the files, default to 1 second.
+	Interval time.Duration
+	// Debounce is how long files have to stay unchanged before the task restarts, so a burst of writes
+	// (e.g. a cert and its key) restarts it once. default to Interval.
+	Debounce time.Duration
+}
+
+// FileWatch is a handle to a file watch, the task running the watch itself; cancel it to stop watching.
+type FileWatch struct {
+	*TaskStatus
+
+	mutex   sync.Mutex
+	current *TaskStatus
+	runs    int
+}
+
+// Current returns the latest run of the watched task.
+func (w *FileWatch) Current() *TaskStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.current
+}
+
+// Runs returns number of times the watched task started, the first run included.
+func (w *FileWatch) Runs() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.runs
+}
+
+// WatchFiles runs fn as a task right away, and again each time files matching pattern (see filepath.Match) change:
+// modified, created or removed. files are polled every Interval, and rapid changes are coalesced, see WatchOptions.
+// a run still going when files change is canceled, and waited for, before the next one starts, e.g. for config reload.
+// runs are started with opts. context passed in may impact lifetime of the watch, and of its runs.
+// a malformed pattern is rejected with filepath.ErrBadPattern, before anything runs.
+func WatchFiles(ctx context.Context, pattern string, fn AsyncFunc, options *WatchOptions, opts ...TaskOption) (*FileWatch, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("watch %q: %w", pattern, err)
+	}
+	if options == nil {
+		options = &WatchOptions{}
+	}
+	interval := options.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	debounce := options.Debounce
+	if debounce <= 0 {
+		debounce = interval
+	}
+
+	w := &FileWatch{}
+	// set before the watch routine can run, so Current is never nil.
+	w.current = Start(ctx, fn, opts...)
+	w.runs = 1
+	w.TaskStatus = Start(ctx, func(ctx context.Context) (interface{}, error) {
+		last := scanFiles(pattern)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var changedAt time.Time
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				// watch is over, and so is its run.
+				w.Current().Cancel()
+				return w.Runs(), ctx.Err()
+			}
+
+			if stamps := scanFiles(pattern); !sameFiles(last, stamps) {
+				last = stamps
+				changedAt = time.Now()
+			}
+			if changedAt.IsZero() || time.Since(changedAt) < debounce {
+				continue
+			}
+			changedAt = time.Time{}
+			if err := w.restart(ctx, fn, opts); err != nil {
+				return w.Runs(), err
+			}
+		}
+	})
+	return w, nil
+}
+
+// restart cancel the current run, wait for it, and starts the next one.
+func (w *FileWatch) restart(ctx context.Context, fn AsyncFunc, opts []TaskOption) error {
+	current := w.Current()
+	current.Cancel()
+	if _, err := current.waitFinished(ctx); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	next := Start(ctx, fn, opts...)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.current = next
+	w.runs++
+	return nil
+}
+
+// fileStamp tells a file changed.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// scanFiles returns stamps of files matching pattern, which is well formed.
+func scanFiles(pattern string) map[string]fileStamp {
+	paths, _ := filepath.Glob(pattern)
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps
+}
+
+func sameFiles(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
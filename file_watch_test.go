@@ -0,0 +1,70 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchFiles(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	dir, err := ioutil.TempDir("", "watch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cert := filepath.Join(dir, "tls.crt")
+	assert.NoError(t, ioutil.WriteFile(cert, []byte("v1"), 0600))
+
+	loads := int32(0)
+	reload := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		// serves until the next reload.
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	_, err = asynctask.WatchFiles(ctx, filepath.Join(dir, "[.crt"), reload, nil)
+	assert.True(t, errors.Is(err, filepath.ErrBadPattern), "expecting ErrBadPattern")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&loads))
+
+	watch, err := asynctask.WatchFiles(ctx, filepath.Join(dir, "*.crt"), reload,
+		&asynctask.WatchOptions{Interval: 5 * time.Millisecond, Debounce: 30 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&loads) == 1 }, time.Second, time.Millisecond)
+	first := watch.Current()
+
+	// a burst of writes restarts once.
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, ioutil.WriteFile(cert, []byte(strings.Repeat("v", i+2)), 0600))
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return watch.Runs() == 2 }, time.Second, time.Millisecond)
+	_, err = first.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 2, watch.Runs())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	// new file, and files not matching don't count.
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0600))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 2, watch.Runs())
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ca.crt"), []byte("ca"), 0600))
+	assert.Eventually(t, func() bool { return watch.Runs() == 3 }, time.Second, time.Millisecond)
+
+	// stop watching, current run is canceled.
+	current := watch.Current()
+	watch.Cancel()
+	_, err = current.Wait(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), "run is canceled with the watch")
+}
@@ -0,0 +1,21 @@
+package asynctask
+
+import "context"
+
+// Go run a async function no one is going to Wait on, in place of a bare go statement.
+// error of the task, panic (ErrPanic) and cancel (ErrCanceled) included, is passed to errSink once it finishes,
+// errSink runs on the routine which finished the task, see OnDone.
+// returned handle can still be used to Cancel the task, or Track it in a Registry.
+func Go(ctx context.Context, task AsyncFunc, errSink func(error), opts ...TaskOption) *TaskStatus {
+	if errSink == nil {
+		panic("asynctask: Go requires an error sink")
+	}
+
+	tsk := Start(ctx, task, opts...)
+	tsk.onDone(func() {
+		if _, err := tsk.outcome(); err != nil && err != ErrResultReleased {
+			errSink(err)
+		}
+	})
+	return tsk
+}
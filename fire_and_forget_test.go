@@ -0,0 +1,47 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGo(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errs := make(chan error, 3)
+	sink := func(err error) { errs <- err }
+
+	asynctask.Go(ctx, getErrorTask("dummy error", time.Millisecond), sink)
+	assert.Equal(t, "dummy error", (<-errs).Error())
+
+	asynctask.Go(ctx, getPanicTask(time.Millisecond), sink)
+	assert.True(t, errors.Is(<-errs, asynctask.ErrPanic), "expecting ErrPanic")
+
+	tsk := asynctask.Go(ctx, func(fCtx context.Context) (interface{}, error) {
+		<-fCtx.Done()
+		return nil, fCtx.Err()
+	}, sink)
+	tsk.Cancel()
+	assert.Equal(t, asynctask.ErrCanceled, <-errs)
+
+	// success doesn't reach the sink.
+	tsk = asynctask.Go(ctx, getCountingTask(3, time.Millisecond), sink)
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected error %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.Panics(t, func() {
+		asynctask.Go(ctx, getCountingTask(3, time.Millisecond), nil)
+	})
+}
@@ -0,0 +1,79 @@
+package asynctask
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTimeout is returned if the function wrapped with WithFuncTimeout, or task started WithTimeout, didn't finish in time,
+// it satisfies errors.Is(err, context.DeadlineExceeded).
+var ErrTimeout = fmt.Errorf("task timeout: %w", context.DeadlineExceeded)
+
+// abandonedRoutines is number of routines left behind by WithFuncTimeout, and still running.
+var abandonedRoutines int64
+
+// AbandonedRoutines returns number of routines WithFuncTimeout gave up on, which are still running.
+// keep an eye on it, a growing number means wrapped functions never return, and leak.
+func AbandonedRoutines() int64 {
+	return atomic.LoadInt64(&abandonedRoutines)
+}
+
+// WithFuncTimeout wraps fn so it fails with ErrTimeout if it doesn't return within d, even if fn ignores its context.
+// fn runs on its own routine with a context canceled after d, on timeout that routine is abandoned:
+// it keeps running until fn returns (forever if fn never does), and its result is dropped.
+// only use it on functions you can't fix to honor context, see AbandonedRoutines to track the leak.
+func WithFuncTimeout(fn AsyncFunc, d time.Duration) AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		fCtx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		type outcome struct {
+			result interface{}
+			err    error
+		}
+
+		const (
+			pending int32 = iota
+			returned
+			abandoned
+		)
+		state := pending
+		done := make(chan outcome, 1)
+		go func() {
+			var o outcome
+			defer func() {
+				if r := recover(); r != nil {
+					o.err = newPanicError(r)
+				}
+				if atomic.CompareAndSwapInt32(&state, pending, returned) {
+					done <- o
+					return
+				}
+				atomic.AddInt64(&abandonedRoutines, -1)
+			}()
+			o.result, o.err = fn(fCtx)
+		}()
+
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-fCtx.Done():
+		}
+
+		// counted before handing over, so the routine never decrements ahead of us.
+		atomic.AddInt64(&abandonedRoutines, 1)
+		if !atomic.CompareAndSwapInt32(&state, pending, abandoned) {
+			// fn returned just now.
+			atomic.AddInt64(&abandonedRoutines, -1)
+			o := <-done
+			return o.result, o.err
+		}
+		if err := ctx.Err(); err != nil {
+			// task itself is done, not our timeout.
+			return nil, err
+		}
+		return nil, ErrTimeout
+	}
+}
@@ -0,0 +1,49 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFuncTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, asynctask.WithFuncTimeout(getCountingTask(3, time.Millisecond), time.Second))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+
+	tsk = asynctask.Start(ctx, asynctask.WithFuncTimeout(getPanicTask(0), time.Second))
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+}
+
+func TestWithFuncTimeoutAbandon(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// ignores context, and only return once released.
+	release := make(chan struct{})
+	tsk := asynctask.Start(ctx, asynctask.WithFuncTimeout(func(context.Context) (interface{}, error) {
+		<-release
+		return 1, nil
+	}, 10*time.Millisecond))
+
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrTimeout, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+	assert.Equal(t, int64(1), asynctask.AbandonedRoutines())
+
+	close(release)
+	for asynctask.AbandonedRoutines() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
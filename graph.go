@@ -0,0 +1,465 @@
+package asynctask
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrDependencyFailed is returned for a graph node which never ran, because one of its dependencies didn't complete.
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// ErrInvalidGraph is returned by Graph.Validate (and Start) for a graph which can't run: duplicate node names,
+// unknown dependencies or cycles, see CycleError.
+var ErrInvalidGraph = errors.New("invalid graph")
+
+// ErrNoPreviousRun is returned by Graph.Resume given no previous run, for a graph without checkpointer to load it from.
+var ErrNoPreviousRun = errors.New("no previous run to resume")
+
+// CycleError is a dependency cycle found in a graph, it satisfies errors.Is(err, ErrInvalidGraph).
+type CycleError struct {
+	// Path lists nodes of the cycle, starting and ending with the same node, each node depending on the next one.
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: dependency cycle %s", ErrInvalidGraph, strings.Join(e.Path, " -> "))
+}
+
+// Unwrap returns ErrInvalidGraph.
+func (e *CycleError) Unwrap() error {
+	return ErrInvalidGraph
+}
+
+// Graph is a set of named tasks with dependencies between them,
+// Run executes every task once all its dependencies completed, independent tasks in parallel.
+type Graph struct {
+	nodes          []*graphNode
+	conditions     []*graphCondition
+	checkpointSave CheckpointSaveFunc
+	checkpointLoad CheckpointLoadFunc
+}
+
+// graphCheckpoint is what a graph persists through its checkpointer, see Graph.WithCheckpointer.
+type graphCheckpoint struct {
+	// Results are JSON encoded results of completed nodes, by name.
+	Results map[string]json.RawMessage `json:"results"`
+}
+
+// graphCondition guards edge from node to dep, see When.
+type graphCondition struct {
+	node      string
+	dep       string
+	predicate func(result interface{}) bool
+}
+
+type graphNode struct {
+	name string
+	fn   AsyncFunc
+	deps []string
+	opts []TaskOption
+}
+
+// NewGraph returns an empty graph.
+func NewGraph() *Graph {
+	return &Graph{}
+}
+
+// AddNode adds a task named name to the graph, which runs fn once every node in deps completed.
+// the task is started with opts, and WithName(name).
+func (g *Graph) AddNode(name string, fn AsyncFunc, deps []string, opts ...TaskOption) *Graph {
+	g.nodes = append(g.nodes, &graphNode{name: name, fn: fn, deps: deps, opts: opts})
+	return g
+}
+
+// When guards the edge from node to its dependency dep: node runs only if predicate returns true on result of dep,
+// it's StateSkipped otherwise. nodes depending on a skipped node are skipped as well, unless another dependency failed.
+// e.g. run the DNS update only if the IP changed.
+func (g *Graph) When(node, dep string, predicate func(result interface{}) bool) *Graph {
+	g.conditions = append(g.conditions, &graphCondition{node: node, dep: dep, predicate: predicate})
+	return g
+}
+
+// WithCheckpointer persists result of each completed node, as JSON, through save, so the graph can be resumed
+// after process restart, see Resume. a node whose result can't be encoded or saved fails.
+func (g *Graph) WithCheckpointer(save CheckpointSaveFunc, load CheckpointLoadFunc) *Graph {
+	g.checkpointSave = save
+	g.checkpointLoad = load
+	return g
+}
+
+// GraphRun is a handle to an execution of a graph.
+type GraphRun struct {
+	graph *Graph
+	tasks map[string]*TaskStatus
+
+	// checkpointMutex serializes saves of checkpoint, which holds results of completed nodes.
+	checkpointMutex sync.Mutex
+	checkpoint      map[string]json.RawMessage
+}
+
+type graphRunContextKey struct{}
+
+// Run executes the graph, and block until every node finished, see Start.
+func (g *Graph) Run(ctx context.Context) (*GraphRun, error) {
+	run, err := g.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return run, run.Wait(ctx)
+}
+
+// Start executes the graph, and returns a handle right away.
+// a node is in StateScheduled until its dependencies finished, it fails with ErrDependencyFailed if any of them failed or got canceled,
+// and it's skipped if any of them is skipped, or a condition on its edges isn't met, see When.
+// context passed in may impact lifetime of every node.
+// invalid graph is rejected before any node runs, see Validate.
+func (g *Graph) Start(ctx context.Context) (*GraphRun, error) {
+	return g.start(ctx, nil, map[string]json.RawMessage{})
+}
+
+// Resume executes the graph again from where previous run of it broke: nodes which completed in previous run are not run,
+// their results are reused, everything else (failed, canceled, skipped nodes, and their dependents) runs again.
+// previous nil resumes from the checkpoint of the graph instead, e.g. after process restart, see WithCheckpointer;
+// results loaded are decoded from JSON into interface{}, e.g. numbers are float64.
+// ErrNoPreviousRun is returned if previous is nil and the graph has no checkpointer.
+// it returns right away like Start, previous run should be finished.
+func (g *Graph) Resume(ctx context.Context, previous *GraphRun) (*GraphRun, error) {
+	if previous == nil {
+		if g.checkpointLoad == nil {
+			return nil, ErrNoPreviousRun
+		}
+		return g.resumeFromCheckpoint(ctx)
+	}
+
+	reused := map[string]*TaskStatus{}
+	checkpoint := map[string]json.RawMessage{}
+	for name, tsk := range previous.tasks {
+		if tsk.State() != StateCompleted {
+			continue
+		}
+		reused[name] = tsk
+		if g.checkpointSave != nil {
+			result, _ := tsk.outcome()
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("checkpoint result of %q: %w", name, err)
+			}
+			checkpoint[name] = encoded
+		}
+	}
+	return g.start(ctx, reused, checkpoint)
+}
+
+// resumeFromCheckpoint executes the graph reusing results of completed nodes loaded from its checkpointer.
+func (g *Graph) resumeFromCheckpoint(ctx context.Context) (*GraphRun, error) {
+	state, err := g.checkpointLoad(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := graphCheckpoint{Results: map[string]json.RawMessage{}}
+	if state != nil {
+		if err := json.Unmarshal(state, &checkpoint); err != nil {
+			return nil, fmt.Errorf("load graph checkpoint: %w", err)
+		}
+	}
+
+	reused := make(map[string]*TaskStatus, len(checkpoint.Results))
+	for name, encoded := range checkpoint.Results {
+		var result interface{}
+		if err := json.Unmarshal(encoded, &result); err != nil {
+			return nil, fmt.Errorf("load result of %q: %w", name, err)
+		}
+		reused[name] = newFinishedTask(StateCompleted, result, nil, newTaskOptions([]TaskOption{WithName(name)}))
+	}
+	return g.start(ctx, reused, checkpoint.Results)
+}
+
+// start executes the graph, reusing tasks of completed nodes from a previous run, whose results are in checkpoint.
+func (g *Graph) start(ctx context.Context, reused map[string]*TaskStatus, checkpoint map[string]json.RawMessage) (*GraphRun, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	run := &GraphRun{graph: g, tasks: make(map[string]*TaskStatus, len(g.nodes)), checkpoint: checkpoint}
+	ctx = context.WithValue(ctx, graphRunContextKey{}, run)
+
+	launches := make([]func(), 0, len(g.nodes))
+	for _, node := range g.nodes {
+		if tsk, ok := reused[node.name]; ok {
+			run.tasks[node.name] = tsk
+			launches = append(launches, func() {})
+			continue
+		}
+		launches = append(launches, run.schedule(ctx, node))
+	}
+
+	for i, node := range g.nodes {
+		launch := launches[i]
+		if len(node.deps) == 0 {
+			launch()
+			continue
+		}
+
+		mutex := sync.Mutex{}
+		pending := len(node.deps)
+		for _, dep := range node.deps {
+			run.tasks[dep].onDone(func() {
+				mutex.Lock()
+				pending--
+				ready := pending == 0
+				mutex.Unlock()
+				if ready {
+					launch()
+				}
+			})
+		}
+	}
+
+	return run, nil
+}
+
+// schedule creates task of node, and returns function starting it once dependencies are done.
+func (r *GraphRun) schedule(ctx context.Context, node *graphNode) (launch func()) {
+	options := newTaskOptions(append([]TaskOption{WithName(node.name)}, node.opts...))
+	ctx, release := options.taskContext(ctx)
+	taskCtx, cancel := context.WithCancel(ctx)
+	record := newRunningTask(cancel, options)
+	record.state = StateScheduled
+	record.trackDefault()
+	record.onDone(release)
+	r.tasks[node.name] = record
+
+	stop := failOnContextDone(ctx, taskCtx, record)
+	return func() {
+		if !stop() || record.State().IsTerminalState() {
+			return
+		}
+		skip := false
+		for _, dep := range node.deps {
+			switch state := r.tasks[dep].State(); state {
+			case StateCompleted:
+			case StateSkipped:
+				skip = true
+			default:
+				record.finish(StateFailed, nil, fmt.Errorf("%w: %q is %s", ErrDependencyFailed, dep, state))
+				return
+			}
+		}
+		met, err := r.conditionsMet(node.name)
+		if err != nil {
+			record.finish(StateFailed, nil, err)
+			return
+		}
+		if skip || !met {
+			record.finish(StateSkipped, nil, nil)
+			return
+		}
+		go runAndTrackTask(taskCtx, record, r.checkpointed(node))
+	}
+}
+
+// checkpointed returns function of node, which saves its result once it completed, if the graph has a checkpointer.
+func (r *GraphRun) checkpointed(node *graphNode) AsyncFunc {
+	if r.graph.checkpointSave == nil {
+		return node.fn
+	}
+	return func(ctx context.Context) (interface{}, error) {
+		result, err := node.fn(ctx)
+		if err != nil {
+			return result, err
+		}
+		return result, r.saveResult(ctx, node.name, result)
+	}
+}
+
+// saveResult adds result of node name to the checkpoint, and saves it.
+func (r *GraphRun) saveResult(ctx context.Context, name string, result interface{}) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("checkpoint result of %q: %w", name, err)
+	}
+
+	r.checkpointMutex.Lock()
+	defer r.checkpointMutex.Unlock()
+	r.checkpoint[name] = encoded
+	state, err := json.Marshal(graphCheckpoint{Results: r.checkpoint})
+	if err == nil {
+		err = r.graph.checkpointSave(ctx, state)
+	}
+	if err != nil {
+		// node fails, it's not in the checkpoint either.
+		delete(r.checkpoint, name)
+	}
+	return err
+}
+
+// Validate checks the graph can run: node names are unique, dependencies exist, and there is no cycle.
+// every problem found is reported, as an AggregateError of errors satisfying errors.Is(err, ErrInvalidGraph).
+func (g *Graph) Validate() error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, node := range g.nodes {
+		if seen[node.name] {
+			errs = append(errs, fmt.Errorf("%w: duplicate node %q", ErrInvalidGraph, node.name))
+		}
+		seen[node.name] = true
+	}
+	for _, node := range g.nodes {
+		for _, dep := range node.deps {
+			if !seen[dep] {
+				errs = append(errs, fmt.Errorf("%w: node %q depends on unknown node %q", ErrInvalidGraph, node.name, dep))
+			}
+		}
+	}
+	for _, condition := range g.conditions {
+		if node := g.node(condition.node); node == nil || !containsString(node.deps, condition.dep) {
+			errs = append(errs, fmt.Errorf("%w: condition on unknown edge from %q to %q", ErrInvalidGraph, condition.node, condition.dep))
+		}
+	}
+	for _, cycle := range g.cycles() {
+		errs = append(errs, &CycleError{Path: cycle})
+	}
+	return aggregate(errs)
+}
+
+// cycles returns dependency cycles of the graph, found by depth first search from each node in order added.
+func (g *Graph) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := map[string]int{}
+	var stack []string
+	var cycles [][]string
+
+	var visit func(name string)
+	visit = func(name string) {
+		marks[name] = visiting
+		stack = append(stack, name)
+		if node := g.node(name); node != nil {
+			for _, dep := range node.deps {
+				switch marks[dep] {
+				case unvisited:
+					visit(dep)
+				case visiting:
+					// dep is on the stack, path from it to here, back to it, is a cycle.
+					for i := len(stack) - 1; i >= 0; i-- {
+						if stack[i] == dep {
+							cycle := append(append([]string{}, stack[i:]...), dep)
+							cycles = append(cycles, cycle)
+							break
+						}
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		marks[name] = visited
+	}
+
+	for _, node := range g.nodes {
+		if marks[node.name] == unvisited {
+			visit(node.name)
+		}
+	}
+	return cycles
+}
+
+// conditionsMet runs predicates guarding edges of node on results of its dependencies, which all completed.
+// a panicking predicate is returned as PanicError, for the node to fail with.
+func (r *GraphRun) conditionsMet(name string) (met bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			met, err = false, newPanicError(p)
+		}
+	}()
+	for _, condition := range r.graph.conditions {
+		if condition.node != name {
+			continue
+		}
+		result, _ := r.tasks[condition.dep].outcome()
+		if !condition.predicate(result) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (g *Graph) node(name string) *graphNode {
+	for _, node := range g.nodes {
+		if node.name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+// Task returns task of the node, nil if there is no such node.
+func (r *GraphRun) Task(name string) *TaskStatus {
+	return r.tasks[name]
+}
+
+// Results returns outcome of every node by name, Index is the order node was added.
+func (r *GraphRun) Results() map[string]TaskResult {
+	results := make(map[string]TaskResult, len(r.tasks))
+	for i, node := range r.graph.nodes {
+		tsk := r.tasks[node.name]
+		result, err := tsk.outcome()
+		results[node.name] = TaskResult{Index: i, Task: tsk, Result: result, Err: err}
+	}
+	return results
+}
+
+// Wait block until every node finished.
+// error of the first failed node (in order nodes were added) is returned, nodes failed with ErrDependencyFailed only if no other one failed.
+func (r *GraphRun) Wait(ctx context.Context) error {
+	var firstErr, firstDependencyErr error
+	for _, node := range r.graph.nodes {
+		_, err := r.tasks[node.name].waitFinished(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDependencyFailed):
+			if firstDependencyErr == nil {
+				firstDependencyErr = err
+			}
+		case firstErr == nil:
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	return firstDependencyErr
+}
+
+// UpstreamResult returns result of node name in the graph run ctx belongs to, for a node to consume output of its dependencies.
+// it returns nil if ctx isn't from a graph node, or node name didn't complete.
+func UpstreamResult(ctx context.Context, name string) interface{} {
+	run, _ := ctx.Value(graphRunContextKey{}).(*GraphRun)
+	if run == nil {
+		return nil
+	}
+	tsk := run.tasks[name]
+	if tsk == nil || tsk.State() != StateCompleted {
+		return nil
+	}
+	result, _ := tsk.outcome()
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package asynctask
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// GraphSnapshot is a point-in-time export of a graph, or of a run of it, e.g. to render it in a portal.
+type GraphSnapshot struct {
+	Nodes []GraphNodeSnapshot `json:"nodes"`
+	Edges []GraphEdge         `json:"edges"`
+}
+
+// GraphNodeSnapshot is a node in GraphSnapshot.
+type GraphNodeSnapshot struct {
+	Name string `json:"name"`
+	// Task is snapshot of the node task, nil if exported from a graph not run yet.
+	Task *TaskInfo `json:"task,omitempty"`
+}
+
+// GraphEdge goes from a dependency to the node depending on it, in the direction of execution.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Conditional is set if the edge is guarded, see Graph.When.
+	Conditional bool `json:"conditional,omitempty"`
+}
+
+// graphStateColors are DOT fill colors of node states.
+var graphStateColors = map[State]string{
+	StateScheduled: "white",
+	StateRunning:   "lightblue",
+	StateRetrying:  "lightyellow",
+	StateCompleted: "palegreen",
+	StateFailed:    "salmon",
+	StateCanceled:  "orange",
+	StateSkipped:   "lightgray",
+}
+
+// Snapshot exports nodes and edges of the graph.
+func (g *Graph) Snapshot() GraphSnapshot {
+	return g.snapshot(nil)
+}
+
+// Snapshot exports nodes and edges of the graph, with state and timing of each node, safe to call while the run is going.
+func (r *GraphRun) Snapshot() GraphSnapshot {
+	return r.graph.snapshot(r.tasks)
+}
+
+func (g *Graph) snapshot(tasks map[string]*TaskStatus) GraphSnapshot {
+	snapshot := GraphSnapshot{Nodes: []GraphNodeSnapshot{}, Edges: []GraphEdge{}}
+	for _, node := range g.nodes {
+		nodeSnapshot := GraphNodeSnapshot{Name: node.name}
+		if tsk, ok := tasks[node.name]; ok {
+			info := tsk.Info()
+			nodeSnapshot.Task = &info
+		}
+		snapshot.Nodes = append(snapshot.Nodes, nodeSnapshot)
+
+		for _, dep := range node.deps {
+			snapshot.Edges = append(snapshot.Edges, GraphEdge{From: dep, To: node.name, Conditional: g.conditional(node.name, dep)})
+		}
+	}
+	return snapshot
+}
+
+// conditional tells whether edge from node to dep is guarded.
+func (g *Graph) conditional(node, dep string) bool {
+	for _, condition := range g.conditions {
+		if condition.node == node && condition.dep == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// DOT writes the graph in Graphviz DOT language.
+func (g *Graph) DOT(w io.Writer) error {
+	return g.Snapshot().DOT(w)
+}
+
+// DOT writes the run in Graphviz DOT language, nodes are colored by state and labeled with duration.
+func (r *GraphRun) DOT(w io.Writer) error {
+	return r.Snapshot().DOT(w)
+}
+
+// DOT writes the snapshot in Graphviz DOT language, conditional edges are dashed.
+func (s GraphSnapshot) DOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	for _, node := range s.Nodes {
+		if node.Task == nil {
+			fmt.Fprintf(&b, "\t%q;\n", node.Name)
+			continue
+		}
+		label := fmt.Sprintf("%s\n%s", node.Name, node.Task.State)
+		if duration := node.Task.Duration(); duration > 0 {
+			label += " " + duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q, style=filled, fillcolor=%q];\n", node.Name, label, graphStateColors[node.Task.State])
+	}
+	for _, edge := range s.Edges {
+		if edge.Conditional {
+			fmt.Fprintf(&b, "\t%q -> %q [style=dashed];\n", edge.From, edge.To)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q -> %q;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
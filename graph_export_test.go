@@ -0,0 +1,48 @@
+package asynctask_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphExport(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	graph := asynctask.NewGraph().
+		AddNode("checkIP", func(ctx context.Context) (interface{}, error) {
+			return false, nil
+		}, nil).
+		AddNode("updateDNS", getCountingTask(1, time.Millisecond), []string{"checkIP"}).
+		When("updateDNS", "checkIP", func(result interface{}) bool { return result.(bool) })
+
+	var b strings.Builder
+	assert.NoError(t, graph.DOT(&b))
+	assert.Equal(t, "digraph {\n"+
+		"\t\"checkIP\";\n"+
+		"\t\"updateDNS\";\n"+
+		"\t\"checkIP\" -> \"updateDNS\" [style=dashed];\n"+
+		"}\n", b.String())
+
+	run, err := graph.Run(ctx)
+	assert.NoError(t, err)
+	b.Reset()
+	assert.NoError(t, run.DOT(&b))
+	assert.Contains(t, b.String(), `"updateDNS" [label="updateDNS\nSkipped", style=filled, fillcolor="lightgray"];`)
+	assert.Contains(t, b.String(), `"checkIP" [label="checkIP\nCompleted`)
+
+	snapshot := run.Snapshot()
+	assert.Equal(t, asynctask.StateCompleted, snapshot.Nodes[0].Task.State)
+	assert.Equal(t, []asynctask.GraphEdge{{From: "checkIP", To: "updateDNS", Conditional: true}}, snapshot.Edges)
+	data, err := json.Marshal(snapshot)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"edges":[{"from":"checkIP","to":"updateDNS","conditional":true}]`)
+	assert.Contains(t, string(data), `{"name":"updateDNS","task":{"name":"updateDNS","state":"Skipped"`)
+}
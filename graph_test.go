@@ -0,0 +1,255 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func getGraphNode(name string, order *[]string, mutex *sync.Mutex) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		mutex.Lock()
+		*order = append(*order, name)
+		mutex.Unlock()
+		return name, nil
+	}
+}
+
+func TestGraph(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// build and provision only finish if they run in parallel.
+	started := sync.WaitGroup{}
+	started.Add(2)
+	getParallelNode := func(name string) asynctask.AsyncFunc {
+		return func(ctx context.Context) (interface{}, error) {
+			started.Done()
+			started.Wait()
+			return name, nil
+		}
+	}
+	graph := asynctask.NewGraph().
+		AddNode("deploy", func(ctx context.Context) (interface{}, error) {
+			return []interface{}{asynctask.UpstreamResult(ctx, "build"), asynctask.UpstreamResult(ctx, "provision")}, nil
+		}, []string{"build", "provision"}).
+		AddNode("build", getParallelNode("build"), nil).
+		AddNode("provision", getParallelNode("provision"), nil)
+
+	run, err := graph.Run(ctx)
+	assert.NoError(t, err)
+	results := run.Results()
+	assert.Equal(t, []interface{}{"build", "provision"}, results["deploy"].Result)
+	assert.Equal(t, 0, results["deploy"].Index)
+	assert.Equal(t, "deploy", run.Task("deploy").Info().Name)
+}
+
+func TestGraphFailure(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	order := []string{}
+	mutex := sync.Mutex{}
+	graph := asynctask.NewGraph().
+		AddNode("build", getErrorTask("compile error", time.Millisecond), nil).
+		AddNode("lint", getGraphNode("lint", &order, &mutex), nil).
+		AddNode("deploy", getGraphNode("deploy", &order, &mutex), []string{"build", "lint"}).
+		AddNode("notify", getGraphNode("notify", &order, &mutex), []string{"deploy"})
+
+	run, err := graph.Run(ctx)
+	assert.Equal(t, "compile error", err.Error())
+	assert.Equal(t, []string{"lint"}, order)
+	assert.Equal(t, asynctask.StateCompleted, run.Task("lint").State())
+	for _, name := range []string{"deploy", "notify"} {
+		result := run.Results()[name]
+		assert.True(t, errors.Is(result.Err, asynctask.ErrDependencyFailed), "expecting ErrDependencyFailed for %s", name)
+		assert.Equal(t, asynctask.StateFailed, result.Task.State())
+	}
+
+	// unknown dependency
+	_, err = asynctask.NewGraph().AddNode("deploy", getGraphNode("deploy", &order, &mutex), []string{"build"}).Run(ctx)
+	assert.Error(t, err)
+
+	// canceled before dependencies are done.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	run, err = asynctask.NewGraph().
+		AddNode("slow", getCountingTask(10, 200*time.Millisecond), nil).
+		AddNode("after", getGraphNode("after", &order, &mutex), []string{"slow"}).
+		Start(canceledCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.StateScheduled, run.Task("after").State())
+	cancel()
+	assert.Equal(t, context.Canceled, run.Wait(ctx))
+	assert.Equal(t, asynctask.StateFailed, run.Task("after").State())
+}
+
+func TestGraphValidate(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	ran := false
+	fn := func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	}
+	graph := asynctask.NewGraph().
+		AddNode("build", fn, nil).
+		AddNode("build", fn, nil).
+		AddNode("deploy", fn, []string{"build", "approve"}).
+		AddNode("a", fn, []string{"b"}).
+		AddNode("b", fn, []string{"c"}).
+		AddNode("c", fn, []string{"a"}).
+		AddNode("self", fn, []string{"self"})
+
+	err := graph.Validate()
+	var aggregateErr *asynctask.AggregateError
+	assert.True(t, errors.As(err, &aggregateErr))
+	assert.Len(t, aggregateErr.Errors, 4)
+	for _, err := range aggregateErr.Errors {
+		assert.True(t, errors.Is(err, asynctask.ErrInvalidGraph), "expecting ErrInvalidGraph")
+	}
+	assert.Equal(t, `invalid graph: duplicate node "build"`, aggregateErr.Errors[0].Error())
+	assert.Equal(t, `invalid graph: node "deploy" depends on unknown node "approve"`, aggregateErr.Errors[1].Error())
+	var cycleErr *asynctask.CycleError
+	assert.True(t, errors.As(aggregateErr.Errors[2], &cycleErr))
+	assert.Equal(t, []string{"a", "b", "c", "a"}, cycleErr.Path)
+	assert.Equal(t, "invalid graph: dependency cycle self -> self", aggregateErr.Errors[3].Error())
+
+	// nothing runs.
+	run, err := graph.Run(ctx)
+	assert.Nil(t, run)
+	assert.Error(t, err)
+	assert.False(t, ran)
+
+	assert.NoError(t, asynctask.NewGraph().AddNode("a", fn, nil).AddNode("b", fn, []string{"a"}).AddNode("c", fn, []string{"a", "b"}).Validate())
+}
+
+func TestGraphConditions(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	for _, ipChanged := range []bool{true, false} {
+		order := []string{}
+		mutex := sync.Mutex{}
+		ipChanged := ipChanged
+		graph := asynctask.NewGraph().
+			AddNode("checkIP", func(ctx context.Context) (interface{}, error) {
+				return ipChanged, nil
+			}, nil).
+			AddNode("updateDNS", getGraphNode("updateDNS", &order, &mutex), []string{"checkIP"}).
+			AddNode("flushCache", getGraphNode("flushCache", &order, &mutex), []string{"updateDNS"}).
+			AddNode("report", getGraphNode("report", &order, &mutex), []string{"checkIP"}).
+			When("updateDNS", "checkIP", func(result interface{}) bool { return result.(bool) })
+
+		run, err := graph.Run(ctx)
+		assert.NoError(t, err)
+		if ipChanged {
+			assert.ElementsMatch(t, []string{"updateDNS", "flushCache", "report"}, order)
+			continue
+		}
+		assert.Equal(t, []string{"report"}, order)
+		assert.Equal(t, asynctask.StateSkipped, run.Task("updateDNS").State())
+		// skip carries over to dependents.
+		assert.Equal(t, asynctask.StateSkipped, run.Task("flushCache").State())
+		result, err := run.Task("flushCache").Wait(ctx)
+		assert.Nil(t, result)
+		assert.NoError(t, err)
+	}
+
+	err := asynctask.NewGraph().
+		AddNode("a", getCountingTask(1, time.Millisecond), nil).
+		When("a", "b", func(interface{}) bool { return true }).
+		Validate()
+	assert.Equal(t, `invalid graph: condition on unknown edge from "a" to "b"`, err.Error())
+}
+
+func TestGraphResume(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	order := []string{}
+	mutex := sync.Mutex{}
+	attempts := 0
+	graph := asynctask.NewGraph().
+		AddNode("build", getGraphNode("build", &order, &mutex), nil).
+		AddNode("test", func(ctx context.Context) (interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("flaky test")
+			}
+			return getGraphNode("test", &order, &mutex)(ctx)
+		}, []string{"build"}).
+		AddNode("deploy", func(ctx context.Context) (interface{}, error) {
+			return asynctask.UpstreamResult(ctx, "build"), nil
+		}, []string{"test"})
+
+	first, err := graph.Run(ctx)
+	assert.Equal(t, "flaky test", err.Error())
+	assert.Equal(t, []string{"build"}, order)
+
+	second, err := graph.Resume(ctx, first)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Wait(ctx))
+	// build is not run again, and its result is reused.
+	assert.Equal(t, []string{"build", "test"}, order)
+	assert.Equal(t, first.Task("build"), second.Task("build"))
+	assert.Equal(t, "build", second.Results()["deploy"].Result)
+}
+
+func TestGraphResumeFromCheckpoint(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	_, err := asynctask.NewGraph().Resume(ctx, nil)
+	assert.Equal(t, asynctask.ErrNoPreviousRun, err)
+
+	store := &memoryCheckpoint{}
+	order := []string{}
+	mutex := sync.Mutex{}
+	// newGraph builds the graph as a restarted process would, test fails until fixed.
+	newGraph := func(testFixed bool) *asynctask.Graph {
+		return asynctask.NewGraph().
+			AddNode("build", getGraphNode("build", &order, &mutex), nil).
+			AddNode("test", func(ctx context.Context) (interface{}, error) {
+				if !testFixed {
+					return nil, errors.New("flaky test")
+				}
+				return getGraphNode("test", &order, &mutex)(ctx)
+			}, []string{"build"}).
+			AddNode("deploy", func(ctx context.Context) (interface{}, error) {
+				return asynctask.UpstreamResult(ctx, "build"), nil
+			}, []string{"test"}).
+			WithCheckpointer(store.save, store.load)
+	}
+
+	_, err = newGraph(false).Run(ctx)
+	assert.Equal(t, "flaky test", err.Error())
+
+	run, err := newGraph(true).Resume(ctx, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, run.Wait(ctx))
+	// build is not run again, its result is loaded from the checkpoint.
+	assert.Equal(t, []string{"build", "test"}, order)
+	assert.Equal(t, "build", run.Results()["deploy"].Result)
+
+	// node whose result can't be checkpointed fails.
+	_, err = asynctask.NewGraph().
+		AddNode("channel", func(context.Context) (interface{}, error) {
+			return make(chan int), nil
+		}, nil).
+		WithCheckpointer(store.save, store.load).
+		Run(ctx)
+	assert.Error(t, err)
+}
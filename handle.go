@@ -0,0 +1,85 @@
+package asynctask
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TaskHandle is a per-waiter handle of a shared task, see Handle.
+type TaskHandle struct {
+	task *TaskStatus
+	once sync.Once
+}
+
+// WithCancelWhenAbandoned cancels the task once no live handle (see Handle) remains, so work nobody waits for doesn't linger.
+// on top of Cancel, a handle gives up its interest once a Wait on it returns because the waiter's context is done,
+// or once it's garbage collected.
+func WithCancelWhenAbandoned() TaskOption {
+	return func(o *taskOptions) {
+		o.cancelAbandoned = true
+	}
+}
+
+// Handle returns a handle of the task for one consumer.
+// canceling a handle only gives up interest of that consumer, the task get canceled once every handle is canceled.
+func (t *TaskStatus) Handle() *TaskHandle {
+	t.mutex.Lock()
+	t.handles++
+	t.mutex.Unlock()
+	handle := &TaskHandle{task: t}
+	if t.options.cancelAbandoned {
+		runtime.SetFinalizer(handle, (*TaskHandle).Cancel)
+	}
+	return handle
+}
+
+// releaseHandle drops interest of a handle, and cancel the task if it was the last one.
+func (t *TaskStatus) releaseHandle() {
+	t.mutex.Lock()
+	t.handles--
+	last := t.handles == 0
+	t.mutex.Unlock()
+
+	if last {
+		t.Cancel()
+	}
+}
+
+// Task returns the shared task.
+func (h *TaskHandle) Task() *TaskStatus {
+	return h.task
+}
+
+// State return state of the shared task.
+func (h *TaskHandle) State() State {
+	return h.task.State()
+}
+
+// Wait block until the shared task finished, see TaskStatus.Wait.
+func (h *TaskHandle) Wait(ctx context.Context) (interface{}, error) {
+	result, err := h.task.Wait(ctx)
+	h.abandonOnDone(ctx)
+	return result, err
+}
+
+// WaitWithTimeout block until the shared task finished or timeout, see TaskStatus.WaitWithTimeout.
+func (h *TaskHandle) WaitWithTimeout(ctx context.Context, timeout time.Duration) (interface{}, error) {
+	result, err := h.task.WaitWithTimeout(ctx, timeout)
+	h.abandonOnDone(ctx)
+	return result, err
+}
+
+// abandonOnDone cancels the handle if the waiter's context is done before the task finished, see WithCancelWhenAbandoned.
+func (h *TaskHandle) abandonOnDone(ctx context.Context) {
+	if h.task.options.cancelAbandoned && ctx.Err() != nil && !h.task.State().IsTerminalState() {
+		h.Cancel()
+	}
+}
+
+// Cancel gives up interest of this handle, the shared task get canceled if no other handle is left.
+// only the first call takes effect.
+func (h *TaskHandle) Cancel() {
+	h.once.Do(h.task.releaseHandle)
+}
@@ -0,0 +1,84 @@
+package asynctask_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskHandle(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	first, second := tsk.Handle(), tsk.Handle()
+	assert.Equal(t, tsk, first.Task())
+
+	// one consumer leaves, twice, task keeps running for the other.
+	first.Cancel()
+	first.Cancel()
+	assert.Equal(t, asynctask.StateRunning, second.State())
+	result, err := second.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, result)
+
+	// last consumer leaving cancels the task.
+	tsk = asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	first, second = tsk.Handle(), tsk.Handle()
+	first.Cancel()
+	_, err = second.WaitWithTimeout(ctx, 10*time.Millisecond)
+	assert.Equal(t, asynctask.ErrWaitTimeout, err)
+	second.Cancel()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+}
+
+func TestCancelWhenAbandoned(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	getBlockingTask := func() asynctask.AsyncFunc {
+		return func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}
+
+	// every waiter gave up.
+	tsk := asynctask.Start(ctx, getBlockingTask(), asynctask.WithCancelWhenAbandoned())
+	first, second := tsk.Handle(), tsk.Handle()
+	for _, handle := range []*asynctask.TaskHandle{first, second} {
+		waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Millisecond)
+		_, err := handle.Wait(waitCtx)
+		cancelWait()
+		assert.Equal(t, context.DeadlineExceeded, err)
+	}
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	// handle dropped without cancel.
+	tsk = asynctask.Start(ctx, getBlockingTask(), asynctask.WithCancelWhenAbandoned())
+	tsk.Handle()
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		return tsk.State() == asynctask.StateCanceled
+	}, time.Second, time.Millisecond)
+
+	// without the option, waiter giving up keeps its interest.
+	tsk = asynctask.Start(ctx, getBlockingTask())
+	waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancelWait()
+	_, err = tsk.Handle().Wait(waitCtx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, asynctask.StateRunning, tsk.State())
+	tsk.Cancel()
+}
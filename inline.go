@@ -0,0 +1,33 @@
+package asynctask
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// WithInline declares the task trivially short: if Wait is called before the task routine got scheduled,
+// the waiter runs the function itself, saving the routine round trip.
+// function then runs on the waiter routine till it returns, even past the wait context, and runtime.Goexit would end the waiter.
+// it only applies to Start.
+func WithInline() TaskOption {
+	return func(o *taskOptions) {
+		o.inline = true
+	}
+}
+
+// inlineRun is a function which runs once, either on its own routine or on the first waiter, see WithInline.
+type inlineRun struct {
+	claimed int32
+	ctx     context.Context
+	task    AsyncFunc
+}
+
+// runInline runs the task function on current routine, unless another one already did, returns true if it ran it.
+func (t *TaskStatus) runInline() bool {
+	run := t.inline
+	if run == nil || !atomic.CompareAndSwapInt32(&run.claimed, 0, 1) {
+		return false
+	}
+	runAndTrackTask(run.ctx, t, run.task)
+	return true
+}
@@ -0,0 +1,84 @@
+package asynctask_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// goroutineID parse id of current routine from its stack, for tests only.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, _ := strconv.ParseUint(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	return id
+}
+
+func TestWithInline(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	waiter := goroutineID()
+	inlined := 0
+	for i := 0; i < 100; i++ {
+		tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+			return goroutineID(), nil
+		}, asynctask.WithInline())
+		ranOn, err := tsk.Wait(ctx)
+		assert.NoError(t, err)
+		if ranOn == waiter {
+			inlined++
+		}
+	}
+	// waiter usually gets there before the task routine is scheduled.
+	assert.Greater(t, inlined, 0)
+}
+
+func TestWithInlineRunsOnce(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	runs := int64(0)
+	for i := 0; i < 100; i++ {
+		tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt64(&runs, 1)
+			return "done", nil
+		}, asynctask.WithInline())
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := tsk.Wait(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, "done", result)
+			}()
+		}
+		wg.Wait()
+	}
+	assert.Equal(t, int64(100), atomic.LoadInt64(&runs))
+
+	// never waited, still runs on its own.
+	done := make(chan struct{})
+	asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		close(done)
+		return nil, nil
+	}, asynctask.WithInline())
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("inline task never ran")
+	}
+}
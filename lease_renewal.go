@@ -0,0 +1,75 @@
+package asynctask
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithLeaseRenewal returns a task function running fn while a companion task renews a lease (or lock) every interval,
+// for as long as fn runs. if renew fails, fn's context is canceled, and the task fails with error satisfying
+// errors.Is(err, ErrLeaseLost), wrapping error of renew. companion task is named "lease renewal", and stops with fn.
+func WithLeaseRenewal(fn AsyncFunc, interval time.Duration, renew func(context.Context) error) AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		fnCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		mutex := sync.Mutex{}
+		var renewErr error
+		stopped := make(chan struct{})
+		Start(fnCtx, func(renewCtx context.Context) (interface{}, error) {
+			defer close(stopped)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if err := renew(renewCtx); err != nil {
+						if renewCtx.Err() != nil {
+							// we are stopping, not a lost lease.
+							return nil, nil
+						}
+						mutex.Lock()
+						renewErr = err
+						mutex.Unlock()
+						cancel()
+						return nil, err
+					}
+				case <-renewCtx.Done():
+					return nil, nil
+				}
+			}
+		}, WithName("lease renewal"))
+
+		result, err := fn(fnCtx)
+		// no renewal once fn returned.
+		cancel()
+		<-stopped
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if renewErr != nil {
+			return nil, &leaseLostError{err: renewErr}
+		}
+		return result, err
+	}
+}
+
+// leaseLostError is ErrLeaseLost caused by a failed renewal.
+type leaseLostError struct {
+	err error
+}
+
+func (e *leaseLostError) Error() string {
+	return ErrLeaseLost.Error() + ": " + e.err.Error()
+}
+
+// Is makes errors.Is(err, ErrLeaseLost) work.
+func (e *leaseLostError) Is(target error) bool {
+	return target == ErrLeaseLost
+}
+
+// Unwrap returns the renewal error.
+func (e *leaseLostError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,45 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var errLeaseTaken = errors.New("lease taken by another owner")
+
+func TestWithLeaseRenewal(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var renewals int32
+	renew := func(ctx context.Context) error {
+		atomic.AddInt32(&renewals, 1)
+		return nil
+	}
+	result, err := asynctask.Start(ctx, asynctask.WithLeaseRenewal(getCountingTask(5, 10*time.Millisecond), 5*time.Millisecond, renew)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result)
+	stopped := atomic.LoadInt32(&renewals)
+	assert.True(t, stopped >= 2, "lease should be renewed while task runs")
+	// renewal stops with the task.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&renewals))
+
+	// failed renewal cancels the task.
+	_, err = asynctask.Start(ctx, asynctask.WithLeaseRenewal(func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, 5*time.Millisecond, func(ctx context.Context) error {
+		return errLeaseTaken
+	})).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrLeaseLost), "expecting ErrLeaseLost")
+	assert.True(t, errors.Is(err, errLeaseTaken), "expecting renewal error")
+	assert.Equal(t, "lease lost: lease taken by another owner", err.Error())
+}
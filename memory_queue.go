@@ -0,0 +1,213 @@
+package asynctask
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// defaultKeyRetention is how long MemoryQueue remembers idempotency keys of completed tasks by default.
+const defaultKeyRetention = 24 * time.Hour
+
+// MemoryQueueOptions defines options for NewMemoryQueueWithOptions function
+type MemoryQueueOptions struct {
+	// KeyRetention is how long IdempotencyKey of a completed task is remembered, default to 24 hours.
+	// enqueue with that key returns the completed task meanwhile, so a late client retry doesn't run the job again.
+	KeyRetention time.Duration
+}
+
+// MemoryQueue is an in-memory DurableQueue, it doesn't survive process restart.
+// it's the reference implementation, and handy for tests.
+type MemoryQueue struct {
+	mutex sync.Mutex
+	// order keeps IDs of queued tasks, oldest first.
+	order   []string
+	entries map[string]*memoryQueueEntry
+	// keys maps IdempotencyKey to ID of the task
+	keys map[string]string
+	// completed keeps completed tasks with a key by key, completedOrder has them oldest first, for expiry.
+	completed      map[string]*completedEntry
+	completedOrder []*completedEntry
+	retention      time.Duration
+}
+
+type completedEntry struct {
+	desc        TaskDescriptor
+	completedAt time.Time
+}
+
+type memoryQueueEntry struct {
+	desc  TaskDescriptor
+	lease *Lease
+}
+
+// NewMemoryQueue returns an empty MemoryQueue.
+func NewMemoryQueue() *MemoryQueue {
+	return NewMemoryQueueWithOptions(nil)
+}
+
+// NewMemoryQueueWithOptions is NewMemoryQueue with options.
+func NewMemoryQueueWithOptions(options *MemoryQueueOptions) *MemoryQueue {
+	retention := defaultKeyRetention
+	if options != nil && options.KeyRetention > 0 {
+		retention = options.KeyRetention
+	}
+	return &MemoryQueue{
+		entries:   map[string]*memoryQueueEntry{},
+		keys:      map[string]string{},
+		completed: map[string]*completedEntry{},
+		retention: retention,
+	}
+}
+
+// Enqueue implements DurableQueue.
+func (q *MemoryQueue) Enqueue(ctx context.Context, desc TaskDescriptor) (TaskDescriptor, error) {
+	id, err := newRandomID()
+	if err != nil {
+		return TaskDescriptor{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if desc.IdempotencyKey != "" {
+		if existing, ok := q.keys[desc.IdempotencyKey]; ok {
+			return q.entries[existing].desc, nil
+		}
+		q.expireCompleted()
+		if completed, ok := q.completed[desc.IdempotencyKey]; ok {
+			return completed.desc, nil
+		}
+		q.keys[desc.IdempotencyKey] = id
+	}
+
+	desc.ID = id
+	desc.EnqueuedAt = time.Now()
+	q.entries[id] = &memoryQueueEntry{desc: desc}
+	q.order = append(q.order, id)
+	return desc, nil
+}
+
+// Claim implements DurableQueue.
+func (q *MemoryQueue) Claim(ctx context.Context, worker string, leaseDuration time.Duration) (*Lease, error) {
+	token, err := newRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	for _, id := range q.order {
+		entry := q.entries[id]
+		if entry.lease != nil && now.Before(entry.lease.ExpiresAt) {
+			continue
+		}
+
+		entry.desc.Attempt++
+		entry.lease = &Lease{
+			Descriptor: entry.desc,
+			Token:      token,
+			Worker:     worker,
+			ExpiresAt:  now.Add(leaseDuration),
+		}
+		// hand out a copy, holder shouldn't be able to change what's stored.
+		lease := *entry.lease
+		lease.Descriptor.Errors = append([]string(nil), entry.desc.Errors...)
+		return &lease, nil
+	}
+
+	return nil, ErrQueueEmpty
+}
+
+// Complete implements DurableQueue.
+func (q *MemoryQueue) Complete(ctx context.Context, lease *Lease) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entry, err := q.leasedEntry(lease)
+	if err != nil {
+		return err
+	}
+	q.remove(entry.desc.ID)
+	if key := entry.desc.IdempotencyKey; key != "" {
+		delete(q.keys, key)
+		completed := &completedEntry{desc: entry.desc, completedAt: time.Now()}
+		q.completed[key] = completed
+		q.completedOrder = append(q.completedOrder, completed)
+		q.expireCompleted()
+	}
+	return nil
+}
+
+// expireCompleted forgets completed tasks older than retention, caller should hold the lock.
+func (q *MemoryQueue) expireCompleted() {
+	expired := 0
+	for _, completed := range q.completedOrder {
+		if time.Since(completed.completedAt) < q.retention {
+			break
+		}
+		delete(q.completed, completed.desc.IdempotencyKey)
+		expired++
+	}
+	q.completedOrder = q.completedOrder[expired:]
+}
+
+// Release implements DurableQueue.
+func (q *MemoryQueue) Release(ctx context.Context, lease *Lease, taskErr error) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entry, err := q.leasedEntry(lease)
+	if err != nil {
+		return err
+	}
+	if taskErr != nil {
+		entry.desc.Errors = append(entry.desc.Errors, taskErr.Error())
+	}
+	entry.lease = nil
+
+	// move to the back, so a failing task doesn't block others.
+	q.remove(entry.desc.ID)
+	q.entries[entry.desc.ID] = entry
+	q.order = append(q.order, entry.desc.ID)
+	return nil
+}
+
+// Len returns number of tasks in the queue, leased ones included.
+func (q *MemoryQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.order)
+}
+
+// leasedEntry returns the entry if lease is still held, caller should hold the lock.
+func (q *MemoryQueue) leasedEntry(lease *Lease) (*memoryQueueEntry, error) {
+	entry, ok := q.entries[lease.Descriptor.ID]
+	if !ok || entry.lease == nil || entry.lease.Token != lease.Token || time.Now().After(entry.lease.ExpiresAt) {
+		return nil, ErrLeaseLost
+	}
+	return entry, nil
+}
+
+// remove drops the task from the queue, caller should hold the lock.
+func (q *MemoryQueue) remove(id string) {
+	delete(q.entries, id)
+	for i, queued := range q.order {
+		if queued == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			return
+		}
+	}
+}
+
+func newRandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
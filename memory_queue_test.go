@@ -0,0 +1,125 @@
+package asynctask_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+
+	queue := asynctask.NewMemoryQueue()
+	first, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("tenant-a")})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.ID)
+	second, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", Payload: []byte("tenant-b")})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	lease, err := queue.Claim(ctx, "w1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, lease.Descriptor.ID, "oldest first")
+	assert.Equal(t, 1, lease.Descriptor.Attempt)
+	assert.Equal(t, "w1", lease.Worker)
+
+	// leased one is skipped
+	lease2, err := queue.Claim(ctx, "w2", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, lease2.Descriptor.ID)
+	_, err = queue.Claim(ctx, "w3", time.Minute)
+	assert.Equal(t, asynctask.ErrQueueEmpty, err)
+
+	// release make it claimable again, with error recorded
+	assert.NoError(t, queue.Release(ctx, lease, errors.New("throttled")))
+	lease, err = queue.Claim(ctx, "w3", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, lease.Descriptor.ID)
+	assert.Equal(t, 2, lease.Descriptor.Attempt)
+	assert.Equal(t, []string{"throttled"}, lease.Descriptor.Errors)
+
+	assert.NoError(t, queue.Complete(ctx, lease))
+	assert.NoError(t, queue.Complete(ctx, lease2))
+	assert.Equal(t, 0, queue.Len())
+}
+
+func TestMemoryQueueLeaseExpire(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+
+	queue := asynctask.NewMemoryQueue()
+	_, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export"})
+	assert.NoError(t, err)
+
+	lease, err := queue.Claim(ctx, "w1", 10*time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	// expired lease can be claimed by another worker, and the old holder lose it.
+	lease2, err := queue.Claim(ctx, "w2", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, lease.Descriptor.ID, lease2.Descriptor.ID)
+	assert.Equal(t, asynctask.ErrLeaseLost, queue.Complete(ctx, lease))
+	assert.Equal(t, asynctask.ErrLeaseLost, queue.Release(ctx, lease, nil))
+	assert.NoError(t, queue.Complete(ctx, lease2))
+}
+
+func TestMemoryQueueIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+
+	queue := asynctask.NewMemoryQueue()
+	first, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+
+	// client retry, get the same task back
+	again, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 1, queue.Len())
+
+	// still deduplicated while leased
+	lease, err := queue.Claim(ctx, "w1", time.Minute)
+	assert.NoError(t, err)
+	again, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 1, again.Attempt)
+
+	// a late retry after completion still gets the same task, and nothing is queued
+	assert.NoError(t, queue.Complete(ctx, lease))
+	again, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 0, queue.Len())
+
+	// tasks without key are never deduplicated
+	_, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export"})
+	assert.NoError(t, err)
+	_, err = queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, queue.Len())
+}
+
+func TestMemoryQueueKeyRetention(t *testing.T) {
+	t.Parallel()
+	ctx := newTestContext(t)
+
+	queue := asynctask.NewMemoryQueueWithOptions(&asynctask.MemoryQueueOptions{KeyRetention: 20 * time.Millisecond})
+	first, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+	lease, err := queue.Claim(ctx, "w1", time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, queue.Complete(ctx, lease))
+
+	// once retention passed, the key can be used again
+	time.Sleep(30 * time.Millisecond)
+	again, err := queue.Enqueue(ctx, asynctask.TaskDescriptor{Name: "export", IdempotencyKey: "export-tenant-a"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.ID, again.ID)
+	assert.Equal(t, 1, queue.Len())
+}
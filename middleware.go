@@ -0,0 +1,31 @@
+package asynctask
+
+// Middleware wraps the function of a task, e.g. for tracing, quota or logging,
+// info describes the task being started.
+type Middleware func(info TaskInfo, next AsyncFunc) AsyncFunc
+
+// WithMiddleware wraps the task function with middlewares, first one outermost.
+// they run inside middlewares of the pool (see PoolOptions.Middleware), and around all retries of the task.
+func WithMiddleware(middlewares ...Middleware) TaskOption {
+	return func(o *taskOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// wrapMiddleware wraps task with middlewares of the pool and of the task, pool ones outermost.
+func (t *TaskStatus) wrapMiddleware(task AsyncFunc) AsyncFunc {
+	var middlewares []Middleware
+	if t.pool != nil {
+		middlewares = append(middlewares, t.pool.options.Middleware...)
+	}
+	middlewares = append(middlewares, t.options.middlewares...)
+	if len(middlewares) == 0 {
+		return task
+	}
+
+	info := t.Info()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		task = middlewares[i](info, task)
+	}
+	return task
+}
@@ -0,0 +1,82 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRecordingMiddleware(name string, calls *[]string, mutex *sync.Mutex) asynctask.Middleware {
+	return func(info asynctask.TaskInfo, next asynctask.AsyncFunc) asynctask.AsyncFunc {
+		return func(ctx context.Context) (interface{}, error) {
+			mutex.Lock()
+			*calls = append(*calls, name+":"+info.Name)
+			mutex.Unlock()
+			return next(ctx)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	calls := []string{}
+	mutex := sync.Mutex{}
+	pool := asynctask.NewPool(&asynctask.PoolOptions{
+		Workers:    1,
+		Middleware: []asynctask.Middleware{getRecordingMiddleware("tracing", &calls, &mutex)},
+	})
+	defer pool.Close()
+
+	result, err := pool.Submit(ctx, getCountingTask(3, time.Millisecond),
+		asynctask.WithName("export"),
+		asynctask.WithMiddleware(getRecordingMiddleware("logging", &calls, &mutex), getRecordingMiddleware("quota", &calls, &mutex)),
+	).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+	// every submitted task gets pool middleware.
+	_, err = pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithName("plain")).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tracing:export", "logging:export", "quota:export", "tracing:plain"}, calls)
+
+	// task middleware can short circuit.
+	tsk := asynctask.Start(ctx, getCountingTask(3, time.Millisecond), asynctask.WithMiddleware(
+		func(info asynctask.TaskInfo, next asynctask.AsyncFunc) asynctask.AsyncFunc {
+			return func(ctx context.Context) (interface{}, error) {
+				return nil, errQuotaExceeded
+			}
+		}))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, errQuotaExceeded, err)
+}
+
+func TestMiddlewareAroundRetries(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var calls []string
+	mutex := sync.Mutex{}
+	attempts := 0
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("flaky")
+		}
+		return "done", nil
+	}, asynctask.WithName("flaky"), asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}),
+		asynctask.WithMiddleware(getRecordingMiddleware("trace", &calls, &mutex)))
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", result)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{"trace:flaky"}, calls)
+}
@@ -0,0 +1,209 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrMutexHeld is returned for a task started WithMutexFailFast, if another task holds the mutex.
+var ErrMutexHeld = errors.New("mutex held by another task")
+
+// taskMutexes are process wide mutexes by name, see WithMutex, separate from concurrency limit names.
+// a mutex is removed once no task holds or waits for it.
+var taskMutexes = struct {
+	sync.Mutex
+	mutexes map[string]*taskMutex
+}{mutexes: map[string]*taskMutex{}}
+
+// WithMutex serializes tasks sharing the mutex name across the process, e.g. only one certificate rotation at a time.
+// task waits for the mutex before the function runs, a pool task waits holding its worker.
+// it also waits for tasks holding the mutex shared (see WithSharedMutex), and blocks new ones while waiting.
+func WithMutex(name string) TaskOption {
+	return func(o *taskOptions) {
+		o.mutexName = name
+		o.mutexShared = false
+		o.mutexFailFast = false
+	}
+}
+
+// WithMutexFailFast is WithMutex which fails the task with ErrMutexHeld right away, instead of waiting, if the mutex is held.
+func WithMutexFailFast(name string) TaskOption {
+	return func(o *taskOptions) {
+		o.mutexName = name
+		o.mutexShared = false
+		o.mutexFailFast = true
+	}
+}
+
+// WithSharedMutex holds the mutex name shared: tasks holding it shared run concurrently, e.g. cache reads,
+// while a task WithMutex of the name (e.g. a mutation) runs alone. once such a task waits, new shared ones wait behind it.
+func WithSharedMutex(name string) TaskOption {
+	return func(o *taskOptions) {
+		o.mutexName = name
+		o.mutexShared = true
+		o.mutexFailFast = false
+	}
+}
+
+// taskMutex is a reader/writer lock which can be waited on with a context, waiting writers block new readers.
+type taskMutex struct {
+	mutex          sync.Mutex
+	readers        int
+	writer         bool
+	waitingWriters int
+	// changed is closed and replaced whenever the lock is released, waking up waiters.
+	changed chan struct{}
+	// holders are tasks holding the lock, guarded by waitGraph, see SetStrictWait.
+	holders map[*TaskStatus]struct{}
+	// users is number of tasks holding or waiting for the lock, guarded by taskMutexes,
+	// it's removed from taskMutexes once there is none.
+	users int
+}
+
+// acquireMutex blocks until the task holds its mutex, if it has one, returned release should be called once it's done.
+func (t *TaskStatus) acquireMutex(ctx context.Context) (release func(), err error) {
+	if t.options.mutexName == "" {
+		return func() {}, nil
+	}
+
+	name := t.options.mutexName
+	m := useMutex(name)
+	write := !t.options.mutexShared
+	if err := m.lock(ctx, t, write, t.options.mutexFailFast); err != nil {
+		leaveMutex(name)
+		return nil, err
+	}
+	return func() {
+		m.unlock(t, write)
+		leaveMutex(name)
+	}, nil
+}
+
+// useMutex returns mutex of name, created if it's not in use, leaveMutex should be called once done with it.
+func useMutex(name string) *taskMutex {
+	taskMutexes.Lock()
+	defer taskMutexes.Unlock()
+	m, ok := taskMutexes.mutexes[name]
+	if !ok {
+		m = &taskMutex{changed: make(chan struct{}), holders: map[*TaskStatus]struct{}{}}
+		taskMutexes.mutexes[name] = m
+	}
+	m.users++
+	return m
+}
+
+// leaveMutex drops use of mutex of name, and removes it once nobody uses it.
+func leaveMutex(name string) {
+	taskMutexes.Lock()
+	defer taskMutexes.Unlock()
+	m := taskMutexes.mutexes[name]
+	m.users--
+	if m.users == 0 {
+		delete(taskMutexes.mutexes, name)
+	}
+}
+
+func (m *taskMutex) lock(ctx context.Context, t *TaskStatus, write, failFast bool) error {
+	m.mutex.Lock()
+	if write {
+		m.waitingWriters++
+	}
+	for {
+		if write && !m.writer && m.readers == 0 {
+			m.waitingWriters--
+			m.writer = true
+			m.acquired(t)
+			m.mutex.Unlock()
+			return nil
+		}
+		if !write && !m.writer && m.waitingWriters == 0 {
+			m.readers++
+			m.acquired(t)
+			m.mutex.Unlock()
+			return nil
+		}
+
+		err := ErrMutexHeld
+		if !failFast {
+			err = m.block(t)
+		}
+		if err != nil {
+			if write {
+				// readers held back by us may go.
+				m.waitingWriters--
+				m.notify()
+			}
+			m.mutex.Unlock()
+			return err
+		}
+		changed := m.changed
+		m.mutex.Unlock()
+
+		select {
+		case <-changed:
+			m.mutex.Lock()
+		case <-ctx.Done():
+			m.mutex.Lock()
+			m.unblock(t)
+			if write {
+				m.waitingWriters--
+				m.notify()
+			}
+			m.mutex.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *taskMutex) unlock(t *TaskStatus, write bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if write {
+		m.writer = false
+	} else {
+		m.readers--
+	}
+	waitGraph.Lock()
+	delete(m.holders, t)
+	waitGraph.Unlock()
+	m.notify()
+}
+
+// acquired record t holds the lock, mutex should be held.
+func (m *taskMutex) acquired(t *TaskStatus) {
+	waitGraph.Lock()
+	defer waitGraph.Unlock()
+	t.blockedOn = nil
+	m.holders[t] = struct{}{}
+}
+
+// block record t waits for the lock, ErrSelfWait is returned if a holder waits on t, in strict mode. mutex should be held.
+func (m *taskMutex) block(t *TaskStatus) error {
+	waitGraph.Lock()
+	defer waitGraph.Unlock()
+	if atomic.LoadInt32(&strictWait) != 0 {
+		for holder := range m.holders {
+			if holder.waitsFor(t, map[*TaskStatus]bool{}) {
+				t.blockedOn = nil
+				return ErrSelfWait
+			}
+		}
+	}
+	t.blockedOn = m
+	return nil
+}
+
+// unblock record t no longer waits for the lock, mutex should be held.
+func (m *taskMutex) unblock(t *TaskStatus) {
+	waitGraph.Lock()
+	defer waitGraph.Unlock()
+	t.blockedOn = nil
+}
+
+// notify wakes up waiters, mutex should be held.
+func (m *taskMutex) notify() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
@@ -0,0 +1,52 @@
+package asynctask
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutexRemovedOnceIdle(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFunc()
+
+	const name = "TestMutexRemovedOnceIdle/resource"
+	held, release := make(chan struct{}), make(chan struct{})
+	holding := Start(ctx, func(context.Context) (interface{}, error) {
+		close(held)
+		<-release
+		return nil, nil
+	}, WithMutex(name))
+	<-held
+	waitCtx, cancelWait := context.WithCancel(ctx)
+	waiting := Start(waitCtx, func(context.Context) (interface{}, error) {
+		return nil, nil
+	}, WithMutex(name))
+	failing := Start(ctx, func(context.Context) (interface{}, error) {
+		return nil, nil
+	}, WithMutexFailFast(name))
+	_, err := failing.Wait(ctx)
+	assert.Equal(t, ErrMutexHeld, err)
+
+	// held and waited for, it stays.
+	assert.True(t, mutexInUse(name))
+	cancelWait()
+	_, err = waiting.Wait(ctx)
+	assert.Error(t, err)
+	assert.True(t, mutexInUse(name))
+
+	close(release)
+	_, err = holding.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return !mutexInUse(name) }, time.Second, time.Millisecond)
+}
+
+func mutexInUse(name string) bool {
+	taskMutexes.Lock()
+	defer taskMutexes.Unlock()
+	_, ok := taskMutexes.mutexes[name]
+	return ok
+}
@@ -0,0 +1,115 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMutex(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	first := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-finish
+		return "first", nil
+	}, asynctask.WithMutex("rotate-cert"))
+	<-started
+
+	// fail fast while held.
+	_, err := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutexFailFast("rotate-cert")).Wait(ctx)
+	assert.Equal(t, asynctask.ErrMutexHeld, err)
+
+	// queue behind the holder.
+	second := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutex("rotate-cert"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, time.Time{}, second.Info().StartedAt)
+	// other names are not blocked, neither is concurrency limit of same name.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutex("rotate-key")).Wait(ctx)
+	assert.NoError(t, err)
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithConcurrencyLimit("rotate-cert", 1)).Wait(ctx)
+	assert.NoError(t, err)
+
+	close(finish)
+	_, err = first.Wait(ctx)
+	assert.NoError(t, err)
+	result, err := second.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+
+	// free again.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutexFailFast("rotate-cert")).Wait(ctx)
+	assert.NoError(t, err)
+}
+
+func TestWithSharedMutex(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	readersStarted := make(chan struct{}, 2)
+	finishReaders := make(chan struct{})
+	reader := func(ctx context.Context) (interface{}, error) {
+		readersStarted <- struct{}{}
+		<-finishReaders
+		return "read", nil
+	}
+	// readers run together.
+	read1 := asynctask.Start(ctx, reader, asynctask.WithSharedMutex("cache"))
+	read2 := asynctask.Start(ctx, reader, asynctask.WithSharedMutex("cache"))
+	<-readersStarted
+	<-readersStarted
+
+	// writer waits for readers, and fail fast one doesn't wait at all.
+	_, err := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutexFailFast("cache")).Wait(ctx)
+	assert.Equal(t, asynctask.ErrMutexHeld, err)
+	write := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutex("cache"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, time.Time{}, write.Info().StartedAt)
+
+	// new reader waits behind the writer.
+	read3 := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithSharedMutex("cache"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, time.Time{}, read3.Info().StartedAt)
+
+	close(finishReaders)
+	err = asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, read1, read2, write, read3)
+	assert.NoError(t, err)
+	assert.False(t, read3.Info().StartedAt.Before(write.Info().FinishedAt))
+}
+
+func TestWithSharedMutexCanceledWriter(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	finishReader := make(chan struct{})
+	started := make(chan struct{})
+	read := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-finishReader
+		return nil, nil
+	}, asynctask.WithSharedMutex("config"))
+	<-started
+
+	write := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutex("config"))
+	time.Sleep(10 * time.Millisecond)
+	write.Cancel()
+	_, err := write.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	// readers are no longer held back by the writer who left.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithSharedMutex("config")).Wait(ctx)
+	assert.NoError(t, err)
+
+	close(finishReader)
+	_, err = read.Wait(ctx)
+	assert.NoError(t, err)
+}
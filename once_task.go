@@ -0,0 +1,72 @@
+package asynctask
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OnceOption configures OnceTask.
+type OnceOption func(*onceOptions)
+
+type onceOptions struct {
+	retryOnError bool
+	coolDown     time.Duration
+	taskOpts     []TaskOption
+}
+
+// WithRetryOnError let the next get start the function again once it failed or got canceled,
+// instead of returning the failed task forever. callers already holding the failed task keep it.
+func WithRetryOnError() OnceOption {
+	return func(o *onceOptions) {
+		o.retryOnError = true
+		o.coolDown = 0
+	}
+}
+
+// WithResetOnFailure is WithRetryOnError, once coolDown passed since the task failed,
+// so a broken dependency isn't hammered by every caller. until then callers get the failed task, and fail fast.
+func WithResetOnFailure(coolDown time.Duration) OnceOption {
+	return func(o *onceOptions) {
+		o.retryOnError = true
+		o.coolDown = coolDown
+	}
+}
+
+// WithOnceTaskOptions are options the task is started with, e.g. WithTimeout or WithName.
+func WithOnceTaskOptions(opts ...TaskOption) OnceOption {
+	return func(o *onceOptions) {
+		o.taskOpts = append(o.taskOpts, opts...)
+	}
+}
+
+// OnceTask returns a getter which starts fn on the first call, and returns the same task to every caller,
+// e.g. lazy initialization of a client shared by the process, which callers Wait on with their own context.
+// task runs with values of the context of the first call, but not its cancellation, one caller leaving doesn't cancel it for others.
+// outcome is cached, an error included, see WithRetryOnError and WithResetOnFailure.
+func OnceTask(fn AsyncFunc, opts ...OnceOption) func(ctx context.Context) *TaskStatus {
+	options := &onceOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	taskOpts := append([]TaskOption{WithDetachedContext()}, options.taskOpts...)
+
+	var mutex sync.Mutex
+	var current *TaskStatus
+	return func(ctx context.Context) *TaskStatus {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if current != nil && !(options.retryOnError && current.failedFor(options.coolDown)) {
+			return current
+		}
+		current = Start(ctx, fn, taskOpts...)
+		return current
+	}
+}
+
+// failedFor tells whether the task finished without completing, at least d ago.
+func (t *TaskStatus) failedFor(d time.Duration) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return (t.state == StateFailed || t.state == StateCanceled) && time.Since(t.finishedAt) >= d
+}
@@ -0,0 +1,126 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnceTask(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var runs int32
+	finish := make(chan struct{})
+	getClient := asynctask.OnceTask(func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&runs, 1)
+		<-finish
+		return "client", nil
+	})
+
+	// first caller leaving doesn't cancel it for others.
+	callerCtx, cancelCaller := context.WithCancel(ctx)
+	first := getClient(callerCtx)
+	cancelCaller()
+
+	var wg sync.WaitGroup
+	tasks := make([]*asynctask.TaskStatus, 10)
+	for i := range tasks {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tasks[i] = getClient(ctx)
+		}()
+	}
+	wg.Wait()
+	close(finish)
+
+	for _, tsk := range tasks {
+		assert.True(t, tsk == first, "every caller should get the same task")
+		result, err := tsk.Wait(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "client", result)
+	}
+	assert.True(t, getClient(ctx) == first)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestOnceTaskError(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errInit := errors.New("init failed")
+	failing := func(ctx context.Context) (interface{}, error) {
+		return nil, errInit
+	}
+
+	// error is cached by default.
+	get := asynctask.OnceTask(failing)
+	_, err := get(ctx).Wait(ctx)
+	assert.Equal(t, errInit, err)
+	assert.True(t, get(ctx) == get(ctx))
+
+	// or retried by the next caller.
+	var runs int32
+	getWithRetry := asynctask.OnceTask(func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			return nil, errInit
+		}
+		return "ok", nil
+	}, asynctask.WithRetryOnError(), asynctask.WithOnceTaskOptions(asynctask.WithName("init")))
+	failed := getWithRetry(ctx)
+	_, err = failed.Wait(ctx)
+	assert.Equal(t, errInit, err)
+
+	retried := getWithRetry(ctx)
+	assert.False(t, retried == failed)
+	result, err := retried.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, "init", retried.Info().Name)
+	assert.True(t, getWithRetry(ctx) == retried)
+}
+
+func TestOnceTaskResetOnFailure(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errInit := errors.New("init failed")
+	var runs int32
+	get := asynctask.OnceTask(func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			return nil, errInit
+		}
+		return "ok", nil
+	}, asynctask.WithResetOnFailure(50*time.Millisecond))
+
+	failed := get(ctx)
+	_, err := failed.Wait(ctx)
+	assert.Equal(t, errInit, err)
+
+	// callers fail fast during cool-down.
+	assert.True(t, get(ctx) == failed)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	time.Sleep(60 * time.Millisecond)
+	retried := get(ctx)
+	assert.False(t, retried == failed)
+	result, err := retried.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", result)
+
+	// success is kept.
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, get(ctx) == retried)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
@@ -0,0 +1,72 @@
+package asynctask
+
+import "time"
+
+// TaskOption configures a task at Start.
+type TaskOption func(*taskOptions)
+
+// taskOptions holds configuration of a task, collected from TaskOption.
+type taskOptions struct {
+	name             string
+	labels           map[string]string
+	checkpointSave   CheckpointSaveFunc
+	checkpointLoad   CheckpointLoadFunc
+	releaseOnWait    bool
+	retry            *RetryPolicy
+	detached         bool
+	detachedDeadline bool
+	valueKeys        []interface{}
+	validator        func(interface{}) error
+	classifier       ErrorClassifier
+	timeout          time.Duration
+	stageTimeout     time.Duration
+	attemptTimeout   time.Duration
+	limitName        string
+	mutexName        string
+	mutexFailFast    bool
+	mutexShared      bool
+	limit            int
+	middlewares      []Middleware
+	maxResultSize    int64
+	resultSizer      ResultSizer
+	cancelAbandoned  bool
+	sampleUsage      bool
+	measureCPU       bool
+	inline           bool
+	traceRate        float64
+	probe            func() bool
+	probeInterval    time.Duration
+	process          *processOptions
+	// groupLimiter is concurrency limit of the group task belongs to, see WithGroupConcurrency.
+	groupLimiter chan struct{}
+	// parent is the task which spawned this one, see Spawn.
+	parent *TaskStatus
+	// err is set by an invalid option, task fails with it without running.
+	err error
+}
+
+func newTaskOptions(opts []TaskOption) *taskOptions {
+	options := &taskOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithName gives the task a name, which shows up in TaskInfo.
+func WithName(name string) TaskOption {
+	return func(o *taskOptions) {
+		o.name = name
+	}
+}
+
+// WithLabel attach a key/value label to the task, which shows up in TaskInfo.
+// labels let you find tasks in a Registry, e.g. by tenant.
+func WithLabel(key, value string) TaskOption {
+	return func(o *taskOptions) {
+		if o.labels == nil {
+			o.labels = map[string]string{}
+		}
+		o.labels[key] = value
+	}
+}
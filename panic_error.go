@@ -0,0 +1,76 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// PanicError is the error of a task which panicked, it satisfies errors.Is(err, ErrPanic).
+type PanicError struct {
+	// Value is what the task function panicked with.
+	Value interface{}
+	stack []byte
+}
+
+// Error formats the panic with the formatter set by SetPanicFormatter, "panic: <value>" by default.
+func (e *PanicError) Error() string {
+	return getPanicFormatter()(e)
+}
+
+// Stack returns stack trace of the panicking routine, it's not part of Error() unless the formatter adds it.
+func (e *PanicError) Stack() []byte {
+	return e.stack
+}
+
+// Unwrap returns ErrPanic.
+func (e *PanicError) Unwrap() error {
+	return ErrPanic
+}
+
+// newPanicError wraps recovered value and stack of current routine into a PanicError.
+func newPanicError(r interface{}) error {
+	return &PanicError{Value: r, stack: debug.Stack()}
+}
+
+// PanicFormatter returns message of a PanicError.
+type PanicFormatter func(e *PanicError) string
+
+var globalPanicFormatter atomic.Value
+
+type panicFormatterHolder struct {
+	formatter PanicFormatter
+}
+
+// SetPanicFormatter sets how PanicError is formatted, e.g. to conform to log parsing rules, nil restores the default.
+func SetPanicFormatter(formatter PanicFormatter) {
+	globalPanicFormatter.Store(panicFormatterHolder{formatter: formatter})
+}
+
+// getPanicFormatter returns the global formatter, default one if not set.
+func getPanicFormatter() PanicFormatter {
+	holder, _ := globalPanicFormatter.Load().(panicFormatterHolder)
+	if holder.formatter == nil {
+		return formatPanic
+	}
+	return holder.formatter
+}
+
+// formatPanic is the default PanicFormatter.
+func formatPanic(e *PanicError) string {
+	return fmt.Sprintf("%s: %v", ErrPanic, e.Value)
+}
+
+// MustWait is Wait which panics again if the task panicked, for callers who want panics to reach their own recover or crash handling.
+// it panics with the *PanicError, which has the original value and stack.
+// it is the caller's own wait, like Wait it releases result of a task started WithReleaseOnWait.
+func (t *TaskStatus) MustWait(ctx context.Context) (interface{}, error) {
+	result, err := t.Wait(ctx)
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		panic(panicErr)
+	}
+	return result, err
+}
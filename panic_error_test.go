@@ -0,0 +1,54 @@
+package asynctask_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustWait(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	result, err := asynctask.Start(ctx, getCountingTask(3, time.Millisecond)).MustWait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+
+	_, err = asynctask.Start(ctx, getErrorTask("dummy error", time.Millisecond)).MustWait(ctx)
+	assert.Equal(t, "dummy error", err.Error())
+
+	tsk := asynctask.Start(ctx, getPanicTask(time.Millisecond))
+	func() {
+		defer func() {
+			panicErr, ok := recover().(*asynctask.PanicError)
+			assert.True(t, ok, "expecting *PanicError")
+			assert.Equal(t, "yo", panicErr.Value)
+			// stack is from the panicking routine.
+			assert.Contains(t, string(panicErr.Stack()), "getPanicTask")
+			assert.True(t, errors.Is(panicErr, asynctask.ErrPanic), "expecting ErrPanic")
+		}()
+		_, _ = tsk.MustWait(ctx)
+		assert.Fail(t, "MustWait should panic")
+	}()
+}
+
+func TestPanicFormatter(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	_, err := asynctask.Start(ctx, getPanicTask(time.Millisecond)).Wait(ctx)
+	assert.Equal(t, "panic: yo", err.Error())
+
+	asynctask.SetPanicFormatter(func(e *asynctask.PanicError) string {
+		return fmt.Sprintf("task panicked value=%q stack_bytes=%d", e.Value, len(e.Stack()))
+	})
+	defer asynctask.SetPanicFormatter(nil)
+	var panicErr *asynctask.PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, fmt.Sprintf("task panicked value=\"yo\" stack_bytes=%d", len(panicErr.Stack())), err.Error())
+}
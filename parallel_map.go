@@ -0,0 +1,101 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+)
+
+// MapResult is the outcome of ParallelMap on one item.
+type MapResult[R any] struct {
+	// Index of the item in input.
+	Index int
+	Result[R]
+}
+
+// MapOption configures ParallelMap.
+type MapOption func(*mapOptions)
+
+type mapOptions struct {
+	unordered bool
+}
+
+// Unordered let ParallelMap deliver results as soon as they're ready, instead of in input order.
+// use it when latency matters more than order, e.g. streaming results to a client.
+func Unordered() MapOption {
+	return func(o *mapOptions) {
+		o.unordered = true
+	}
+}
+
+// ParallelMap run fn on each item, at most concurrency at a time, and deliver results on the returned channel.
+// results come in input order by default, regardless of completion order, see Unordered.
+// every item gets a result, items not yet started when ctx is done get ctx.Err().
+// channel is closed after the last result, and buffered to hold all results, so reader can leave early.
+func ParallelMap[T, R any](ctx context.Context, items []T, concurrency int, fn func(context.Context, T) (R, error), opts ...MapOption) <-chan MapResult[R] {
+	options := &mapOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int, len(items))
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+
+	completed := make(chan MapResult[R], len(items))
+	for w := 0; w < concurrency && w < len(items); w++ {
+		go func() {
+			for i := range indexes {
+				completed <- mapOne(ctx, i, items[i], fn)
+			}
+		}()
+	}
+
+	out := make(chan MapResult[R], len(items))
+	go func() {
+		defer close(out)
+		if options.unordered {
+			for range items {
+				out <- <-completed
+			}
+			return
+		}
+
+		pending := map[int]MapResult[R]{}
+		next := 0
+		for range items {
+			result := <-completed
+			pending[result.Index] = result
+			for ready, ok := pending[next]; ok; ready, ok = pending[next] {
+				out <- ready
+				delete(pending, next)
+				next++
+			}
+		}
+	}()
+
+	return out
+}
+
+func mapOne[T, R any](ctx context.Context, index int, item T, fn func(context.Context, T) (R, error)) MapResult[R] {
+	result := MapResult[R]{Index: index}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	rawValue, err := Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		return fn(fCtx, item)
+	}).Wait(ctx)
+	result.Err = err
+	if value, ok := rawValue.(R); ok {
+		result.Value = value
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowerForSmaller makes earlier items finish later.
+func slowerForSmaller(ctx context.Context, item int) (string, error) {
+	time.Sleep(time.Duration(5-item) * 25 * time.Millisecond)
+	if item == 3 {
+		return "", errors.New("bad item")
+	}
+	return strconv.Itoa(item), nil
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var indexes []int
+	var values []string
+	for result := range asynctask.ParallelMap(ctx, []int{0, 1, 2, 3, 4}, 5, slowerForSmaller) {
+		indexes = append(indexes, result.Index)
+		values = append(values, result.Value)
+		if result.Index == 3 {
+			assert.Equal(t, "bad item", result.Err.Error())
+		} else {
+			assert.NoError(t, result.Err)
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indexes)
+	assert.Equal(t, []string{"0", "1", "2", "", "4"}, values)
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var indexes []int
+	for result := range asynctask.ParallelMap(ctx, []int{0, 1, 2, 3, 4}, 5, slowerForSmaller, asynctask.Unordered()) {
+		indexes = append(indexes, result.Index)
+	}
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, indexes)
+
+	// canceled context, every item still get a result.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	count := 0
+	for result := range asynctask.ParallelMap(canceledCtx, []int{0, 1, 2}, 1, slowerForSmaller) {
+		assert.Equal(t, context.Canceled, result.Err)
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
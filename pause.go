@@ -0,0 +1,49 @@
+package asynctask
+
+import "context"
+
+// Pause asks the running task to suspend, it's cooperative: task function is held at its next Checkpoint (or Yield),
+// and the task is in StatePaused until Resume, or until ctx of the function is done.
+// returns false if task isn't running.
+func (t *TaskStatus) Pause() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.state != StateRunning && t.state != StatePaused {
+		return false
+	}
+	if t.resume == nil {
+		t.resume = make(chan struct{})
+	}
+	return true
+}
+
+// Resume lets a paused task continue, returns false if no pause was requested.
+func (t *TaskStatus) Resume() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.resume == nil {
+		return false
+	}
+	close(t.resume)
+	t.resume = nil
+	return true
+}
+
+// holdIfPaused blocks task function at a checkpoint while a pause is requested, or until ctx is done.
+func (t *TaskStatus) holdIfPaused(ctx context.Context) {
+	t.mutex.Lock()
+	resume := t.resume
+	t.mutex.Unlock()
+	if resume == nil || t.transition(StatePaused) != nil {
+		// no pause requested, or task isn't running, e.g. retrying.
+		return
+	}
+
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+
+	// fails if task finished meanwhile, e.g. canceled.
+	_ = t.transition(StateRunning)
+}
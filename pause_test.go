@@ -0,0 +1,70 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPause(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	started := make(chan struct{})
+	loops := 0
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		close(started)
+		for ; loops < 1000; loops++ {
+			if err := asynctask.Checkpoint(ctx); err != nil {
+				return nil, err
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return loops, nil
+	})
+	<-started
+	changes := tsk.StateChanges()
+
+	assert.True(t, tsk.Pause())
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StatePaused))
+	assert.Equal(t, asynctask.StatePaused, tsk.State())
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, asynctask.StatePaused, tsk.State())
+
+	assert.True(t, tsk.Resume())
+	assert.False(t, tsk.Resume())
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, result)
+	assert.False(t, tsk.Pause())
+	assert.Equal(t, []asynctask.State{
+		asynctask.StateRunning, asynctask.StatePaused, asynctask.StateRunning, asynctask.StateCompleted,
+	}, collectStates(changes, time.Second))
+}
+
+func TestPauseCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		for {
+			if err := asynctask.Checkpoint(ctx); err != nil {
+				return nil, err
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	assert.True(t, tsk.Pause())
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StatePaused))
+
+	// paused task can still be canceled.
+	tsk.Cancel()
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, asynctask.StateCanceled, tsk.State())
+}
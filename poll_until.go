@@ -0,0 +1,79 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPollAttemptsExceeded is returned if probe didn't report done within PollUntilOptions.MaxAttempts
+var ErrPollAttemptsExceeded = errors.New("poll attempts exceeded")
+
+// ErrPollDurationExceeded is returned if probe didn't report done within PollUntilOptions.MaxDuration
+var ErrPollDurationExceeded = errors.New("poll duration exceeded")
+
+// ProbeFunc is a function PollUntil invokes repeatedly,
+// it returns the result, and whether the result is final.
+type ProbeFunc func(context.Context) (interface{}, bool, error)
+
+// PollUntilOptions defines options for PollUntil function
+type PollUntilOptions struct {
+	// MaxInterval caps the wait between probes when backing off, zero means no cap.
+	MaxInterval time.Duration
+	// MaxAttempts limits number of probes, zero means no limit.
+	MaxAttempts int
+	// MaxDuration limits time spent on polling, zero means no limit.
+	MaxDuration time.Duration
+}
+
+// PollUntil start a task which invoke probe until it reports done, the task result is the last result from probe.
+// interval is the wait before second probe, and it multiply by backoff after each probe (backoff <= 1 means fixed interval).
+// error from probe fails the task right away.
+func PollUntil(ctx context.Context, interval time.Duration, backoff float64, probe ProbeFunc, options *PollUntilOptions) *TaskStatus {
+	if options == nil {
+		options = &PollUntilOptions{}
+	}
+
+	return Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		var deadline <-chan time.Time
+		if options.MaxDuration > 0 {
+			timer := time.NewTimer(options.MaxDuration)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+
+		for attempt := 1; ; attempt++ {
+			result, done, err := probe(fCtx)
+			if err != nil {
+				return result, err
+			}
+			if done {
+				return result, nil
+			}
+			if options.MaxAttempts > 0 && attempt >= options.MaxAttempts {
+				return result, ErrPollAttemptsExceeded
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-deadline:
+				return result, ErrPollDurationExceeded
+			case <-fCtx.Done():
+				return result, fCtx.Err()
+			}
+
+			interval = nextInterval(interval, backoff, options.MaxInterval)
+		}
+	})
+}
+
+// nextInterval multiply interval by backoff, capped at maxInterval if it's set.
+func nextInterval(interval time.Duration, backoff float64, maxInterval time.Duration) time.Duration {
+	if backoff > 1 {
+		interval = time.Duration(float64(interval) * backoff)
+	}
+	if maxInterval > 0 && interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
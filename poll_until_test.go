@@ -0,0 +1,53 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func getReadyAfterProbe(readyAt int) asynctask.ProbeFunc {
+	probes := 0
+	return func(ctx context.Context) (interface{}, bool, error) {
+		probes++
+		return probes, probes >= readyAt, nil
+	}
+}
+
+func TestPollUntil(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.PollUntil(ctx, time.Millisecond, 2, getReadyAfterProbe(5), &asynctask.PollUntilOptions{MaxInterval: 4 * time.Millisecond})
+	rawResult, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, rawResult)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+}
+
+func TestPollUntilLimits(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.PollUntil(ctx, time.Millisecond, 1, getReadyAfterProbe(5), &asynctask.PollUntilOptions{MaxAttempts: 3})
+	rawResult, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrPollAttemptsExceeded, err)
+	assert.Equal(t, 3, rawResult, "last probe result should be kept")
+
+	tsk = asynctask.PollUntil(ctx, 20*time.Millisecond, 1, getReadyAfterProbe(100), &asynctask.PollUntilOptions{MaxDuration: 50 * time.Millisecond})
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrPollDurationExceeded, err)
+
+	tsk = asynctask.PollUntil(ctx, time.Millisecond, 1, func(ctx context.Context) (interface{}, bool, error) {
+		return nil, false, errors.New("resource not found")
+	}, nil)
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, "resource not found", err.Error())
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+}
@@ -0,0 +1,91 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Poller is the method set of a long-running operation poller,
+// it is satisfied by *runtime.Poller[T] from github.com/Azure/azure-sdk-for-go/sdk/azcore.
+type Poller[T any] interface {
+	Done() bool
+	Poll(context.Context) (*http.Response, error)
+	Result(context.Context) (T, error)
+}
+
+// PollProgress describes one poll of a long-running operation.
+type PollProgress struct {
+	// Attempt counts polls, starting from 1.
+	Attempt int
+	// Response is the http response from the poll.
+	Response *http.Response
+	// NextPoll is how long we wait before next poll, zero if operation is done.
+	NextPoll time.Duration
+}
+
+// PollerOptions defines options for StartPoller function
+type PollerOptions struct {
+	// Frequency is the wait between polls, default to 30 seconds, same as the azure sdk.
+	Frequency time.Duration
+	// MaxFrequency enables backoff: wait doubles after each poll until it reaches MaxFrequency.
+	// zero or anything less than Frequency means no backoff.
+	MaxFrequency time.Duration
+	// OnProgress is invoked after each poll, on the polling routine.
+	OnProgress func(PollProgress)
+}
+
+const defaultPollFrequency = 30 * time.Second
+
+// StartPoller run the poll loop of a long-running operation as a task, result of the task is result of the operation.
+// context passed in may impact task lifetime (from context cancellation), canceled task stop polling.
+func StartPoller[T any](ctx context.Context, poller Poller[T], options *PollerOptions) *TaskStatus {
+	if options == nil {
+		options = &PollerOptions{}
+	}
+
+	return Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		return pollUntilDone(fCtx, poller, options)
+	})
+}
+
+func pollUntilDone[T any](ctx context.Context, poller Poller[T], options *PollerOptions) (interface{}, error) {
+	interval := options.Frequency
+	if interval <= 0 {
+		interval = defaultPollFrequency
+	}
+
+	for attempt := 1; !poller.Done(); attempt++ {
+		resp, err := poller.Poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		progress := PollProgress{Attempt: attempt, Response: resp}
+		if !poller.Done() {
+			progress.NextPoll = interval
+		}
+		if options.OnProgress != nil {
+			options.OnProgress(progress)
+		}
+
+		if progress.NextPoll == 0 {
+			break
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if interval < options.MaxFrequency {
+			interval = nextInterval(interval, 2, options.MaxFrequency)
+		}
+	}
+
+	return poller.Result(ctx)
+}
@@ -0,0 +1,78 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePoller struct {
+	pollsToFinish int
+	polls         int
+	pollErr       error
+}
+
+func (p *fakePoller) Done() bool {
+	return p.polls >= p.pollsToFinish
+}
+
+func (p *fakePoller) Poll(ctx context.Context) (*http.Response, error) {
+	if p.pollErr != nil {
+		return nil, p.pollErr
+	}
+	p.polls++
+	return &http.Response{StatusCode: http.StatusAccepted}, nil
+}
+
+func (p *fakePoller) Result(ctx context.Context) (string, error) {
+	return "provisioned", nil
+}
+
+func TestStartPoller(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var progress []asynctask.PollProgress
+	tsk := asynctask.StartPoller[string](ctx, &fakePoller{pollsToFinish: 4}, &asynctask.PollerOptions{
+		Frequency:    time.Millisecond,
+		MaxFrequency: 3 * time.Millisecond,
+		OnProgress:   func(p asynctask.PollProgress) { progress = append(progress, p) },
+	})
+
+	rawResult, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "provisioned", rawResult)
+	assert.Len(t, progress, 4)
+	assert.Equal(t, time.Millisecond, progress[0].NextPoll)
+	assert.Equal(t, 2*time.Millisecond, progress[1].NextPoll)
+	assert.Equal(t, 3*time.Millisecond, progress[2].NextPoll, "backoff should cap at MaxFrequency")
+	assert.Equal(t, time.Duration(0), progress[3].NextPoll)
+	assert.Equal(t, 4, progress[3].Attempt)
+}
+
+func TestStartPollerFailureCase(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.StartPoller[string](ctx, &fakePoller{pollsToFinish: 4, pollErr: errors.New("throttled")}, nil)
+	_, err := tsk.Wait(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, "throttled", err.Error())
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// cancel stop polling
+	tsk = asynctask.StartPoller[string](ctx, &fakePoller{pollsToFinish: 4}, &asynctask.PollerOptions{Frequency: time.Minute})
+	tsk.Cancel()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+}
@@ -0,0 +1,560 @@
+package asynctask
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned if a task is submitted to a closed pool.
+var ErrPoolClosed = errors.New("pool closed")
+
+// ErrDeadlineBeforeStart is returned if deadline of a submitted task passed while it's still queued.
+var ErrDeadlineBeforeStart = errors.New("deadline passed before task start")
+
+// PanicPolicy decides what a pool does when a task panics, the task itself always fails with ErrPanic.
+type PanicPolicy int
+
+const (
+	// PanicAsError only fails the task, worker moves on to next task.
+	PanicAsError PanicPolicy = iota
+	// PanicRestartWorker retires the worker which ran the task, and starts a fresh one.
+	PanicRestartWorker
+	// PanicShutdownPool closes the pool, queued tasks fail with ErrPoolClosed and running ones get canceled.
+	// Close should still be called to wait for the workers to exit.
+	PanicShutdownPool
+)
+
+// PoolOptions defines options for NewPool function
+type PoolOptions struct {
+	// Workers is number of tasks running at the same time, default to 1.
+	Workers int
+	// MinWorkers is number of workers started up front, default to Workers.
+	// pool grows to Workers when tasks are queued and no worker is idle.
+	MinWorkers int
+	// IdleTimeout is how long a worker above MinWorkers stays idle before it exits, zero means never.
+	IdleTimeout time.Duration
+	// PanicPolicy decides what happens to the pool when a task panics, default to PanicAsError.
+	PanicPolicy PanicPolicy
+	// TenantLabel turns on fair scheduling: queued tasks are grouped by value of this label (see WithLabel),
+	// and dispatch round-robins across the groups, so one tenant flooding the pool doesn't starve others.
+	// within a group tasks are dispatched earliest deadline first, as usual.
+	TenantLabel string
+	// PriorityLabel groups queue waits of started tasks by value of this label (see WithLabel), see PoolStats.QueueWaitByPriority.
+	// it's for metrics only, e.g. to alert when low priority work is starved, dispatch order is not affected.
+	PriorityLabel string
+	// Middleware wraps every task submitted to the pool, outside of middlewares of the task (see WithMiddleware),
+	// so instrumentation can be enforced without touching call sites.
+	Middleware []Middleware
+	// EventSink receives events of tasks submitted to the pool, instead of the global sink (see SetEventSink).
+	EventSink EventSink
+	// AdmissionController runs on Submit with name and labels of the task, a rejected task fails right away
+	// with an *AdmissionError, without being queued.
+	AdmissionController AdmissionController
+}
+
+// Pool runs submitted tasks on a set of workers, fixed unless PoolOptions.MinWorkers is set.
+// queued tasks with a deadline (from context passed to Submit) run earliest deadline first,
+// ahead of tasks without one, which run in submission order.
+type Pool struct {
+	mutex  sync.Mutex
+	wakeup *sync.Cond
+	queue  dispatchQueue
+	seq    uint64
+	closed bool
+	// paused stops dispatching queued tasks, see Pause.
+	paused bool
+	// workerGroup tracks worker routines, for Close to wait on.
+	workerGroup sync.WaitGroup
+	stats       PoolStats
+	options     PoolOptions
+	// workers is number of live workers, idle is number of them waiting for a task.
+	workers int
+	idle    int
+	running map[*TaskStatus]struct{}
+	// waits is recent queue waits by tenant, see PoolStats.QueueWait, priorityWaits by priority.
+	waits         map[string]*waitWindow
+	priorityWaits map[string]*waitWindow
+}
+
+// PoolStats is a snapshot of pool metrics, times are accumulated over all tasks the pool ran.
+type PoolStats struct {
+	// Queued is number of tasks waiting for a worker.
+	Queued int
+	// Running is number of tasks running on workers.
+	Running int
+	// Workers is number of live workers, IdleWorkers of them waiting for a task.
+	Workers     int
+	IdleWorkers int
+	// Started is number of tasks picked up by a worker.
+	Started uint64
+	// Finished is number of tasks finished running on a worker.
+	Finished uint64
+	// QueueTime is time started tasks spent in queue.
+	QueueTime time.Duration
+	// ExecutionTime is time finished tasks spent on a worker.
+	ExecutionTime time.Duration
+	// Panics is number of tasks panicked.
+	Panics uint64
+	// WorkerRestarts is number of workers replaced after a panic, see PanicRestartWorker.
+	WorkerRestarts uint64
+	// Failures is number of tasks finished on a worker with a classified error, by class, see WithErrorClassifier.
+	Failures map[ErrorClass]uint64
+	// QueueWait is queue wait of started tasks by tenant (see PoolOptions.TenantLabel), all under "" without one.
+	// alert on it when tasks of some tenant are starved.
+	QueueWait map[string]QueueWaitStats
+	// QueueWaitByPriority is queue wait of started tasks by priority (see PoolOptions.PriorityLabel), all under "" without one.
+	QueueWaitByPriority map[string]QueueWaitStats
+	// OldestQueued is age of the task waiting longest in queue, zero if queue is empty.
+	OldestQueued time.Duration
+}
+
+// Stats returns current metrics of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := p.stats
+	stats.Queued = p.queued()
+	stats.Workers = p.workers
+	stats.IdleWorkers = p.idle
+	stats.Failures = make(map[ErrorClass]uint64, len(p.stats.Failures))
+	for class, count := range p.stats.Failures {
+		stats.Failures[class] = count
+	}
+	stats.QueueWait = make(map[string]QueueWaitStats, len(p.waits))
+	for tenant, window := range p.waits {
+		stats.QueueWait[tenant] = window.stats()
+	}
+	stats.QueueWaitByPriority = make(map[string]QueueWaitStats, len(p.priorityWaits))
+	for priority, window := range p.priorityWaits {
+		stats.QueueWaitByPriority[priority] = window.stats()
+	}
+	stats.OldestQueued = p.oldestQueued()
+	return stats
+}
+
+// NewPool starts the workers and returns the pool.
+func NewPool(options *PoolOptions) *Pool {
+	p := &Pool{
+		options: PoolOptions{Workers: 1},
+		running: map[*TaskStatus]struct{}{},
+		queue:   &poolQueue{},
+	}
+	if options != nil {
+		p.options = *options
+		if p.options.Workers < 1 {
+			p.options.Workers = 1
+		}
+	}
+	if p.options.TenantLabel != "" {
+		p.queue = newFairQueue(p.options.TenantLabel)
+	}
+	if p.options.MinWorkers < 1 || p.options.MinWorkers > p.options.Workers {
+		p.options.MinWorkers = p.options.Workers
+	}
+
+	p.wakeup = sync.NewCond(&p.mutex)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := 0; i < p.options.MinWorkers; i++ {
+		p.spawnWorker()
+	}
+
+	return p
+}
+
+// Submit queues the function to run on the pool, and returns you a handle which you can Wait or Cancel.
+// context passed in may impact task lifetime (from context cancellation), task canceled or past its deadline while queued never run.
+// values of the context always flow into the task, use WithDetachedContext or WithContextValues if its deadline shouldn't.
+func (p *Pool) Submit(ctx context.Context, task AsyncFunc, opts ...TaskOption) *TaskStatus {
+	options := newTaskOptions(opts)
+	ctx, release := options.taskContext(ctx)
+	taskCtx, cancel := context.WithCancel(ctx)
+	record := newRunningTask(cancel, options)
+	record.onDone(release)
+	record.state = StateQueued
+	record.pool = p
+	if p.options.EventSink != nil {
+		record.sink = p.options.EventSink
+	}
+	record.trackDefault()
+	if p.options.AdmissionController != nil {
+		desc := TaskDescriptor{Name: options.name, Labels: options.labels, EnqueuedAt: record.createdAt}
+		if err := p.options.AdmissionController(desc); err != nil {
+			cancel()
+			record.finish(StateFailed, nil, &AdmissionError{Reason: err.Error(), err: err})
+			return record
+		}
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	item := &poolItem{
+		ctx:         taskCtx,
+		record:      record,
+		task:        task,
+		deadline:    deadline,
+		hasDeadline: hasDeadline,
+	}
+
+	// context done while queued, fail the task without running it.
+	item.stopWatch = failOnContextDone(ctx, taskCtx, record)
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		// finished outside of the lock, sinks and callbacks may use the pool.
+		item.stopWatch()
+		record.finish(StateFailed, nil, ErrPoolClosed)
+		return record
+	}
+
+	p.seq++
+	item.seq = p.seq
+	p.queue.push(item)
+	p.grow()
+	p.wakeup.Signal()
+	p.mutex.Unlock()
+	return record
+}
+
+// grow starts workers for queued tasks no idle worker would pick up, up to Workers, mutex should be held.
+func (p *Pool) grow() {
+	if p.paused {
+		return
+	}
+	for i := p.idle; i < p.queue.Len() && p.workers < p.options.Workers; i++ {
+		p.spawnWorker()
+	}
+}
+
+// Pause stops dispatching queued tasks, running tasks continue, and Submit keeps queueing.
+// queued tasks can still be canceled, or fail on their context.
+func (p *Pool) Pause() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.paused = true
+}
+
+// Resume continues dispatching queued tasks after Pause.
+func (p *Pool) Resume() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.paused = false
+	p.grow()
+	p.wakeup.Broadcast()
+}
+
+// Paused tells whether the pool is paused.
+func (p *Pool) Paused() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.paused
+}
+
+// Close stops accepting new tasks, and block until queued and running tasks finished.
+// a paused pool is resumed, so queued tasks can finish.
+func (p *Pool) Close() {
+	p.mutex.Lock()
+	p.closed = true
+	p.paused = false
+	p.grow()
+	p.wakeup.Broadcast()
+	p.mutex.Unlock()
+
+	p.workerGroup.Wait()
+}
+
+// Len returns number of tasks waiting in queue.
+func (p *Pool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.queue.Len()
+}
+
+// spawnWorker starts a new worker, mutex should be held.
+func (p *Pool) spawnWorker() {
+	p.workers++
+	p.workerGroup.Add(1)
+	go p.work()
+}
+
+// work runs a worker, replacement of a retiring worker is started through work directly, keeping the worker count.
+func (p *Pool) work() {
+	exited := false
+	defer func() {
+		if !exited {
+			// task called runtime.Goexit on this worker, keep the worker count.
+			p.workerGroup.Add(1)
+			go p.work()
+		}
+		p.workerGroup.Done()
+	}()
+	p.serve()
+	exited = true
+}
+
+// serve runs queued tasks until pool is closed, or the worker should retire after a panic.
+func (p *Pool) serve() {
+	for {
+		item := p.next()
+		if item == nil {
+			return
+		}
+		if !p.run(item) {
+			continue
+		}
+
+		switch p.options.PanicPolicy {
+		case PanicRestartWorker:
+			p.mutex.Lock()
+			p.stats.WorkerRestarts++
+			p.mutex.Unlock()
+			p.workerGroup.Add(1)
+			go p.work()
+			return
+		case PanicShutdownPool:
+			p.shutdown()
+		}
+	}
+}
+
+// run executes the task on current worker, and records queue and execution time.
+// returns true if the task panicked.
+func (p *Pool) run(item *poolItem) bool {
+	if !item.ready() {
+		return false
+	}
+
+	start := time.Now()
+	p.mutex.Lock()
+	p.stats.Running++
+	p.stats.Started++
+	wait := start.Sub(item.record.Info().CreatedAt)
+	p.stats.QueueTime += wait
+	p.recordWait(item, wait)
+	p.running[item.record] = struct{}{}
+	p.mutex.Unlock()
+
+	panicked := false
+	// deferred, so bookkeeping still happens if the task calls runtime.Goexit.
+	defer func() {
+		class := item.record.ErrorClass()
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.stats.Running--
+		p.stats.Finished++
+		p.stats.ExecutionTime += time.Since(start)
+		delete(p.running, item.record)
+		if panicked {
+			p.stats.Panics++
+		}
+		if class != "" {
+			if p.stats.Failures == nil {
+				p.stats.Failures = map[ErrorClass]uint64{}
+			}
+			p.stats.Failures[class]++
+		}
+	}()
+
+	panicked = runAndTrackTask(item.ctx, item.record, item.task)
+	return panicked
+}
+
+// shutdown closes the pool, fails queued tasks and cancel running ones.
+func (p *Pool) shutdown() {
+	p.mutex.Lock()
+	p.closed = true
+	queued := make([]*poolItem, 0, p.queue.Len())
+	for p.queue.Len() > 0 {
+		queued = append(queued, p.queue.pop())
+	}
+	running := make([]*TaskStatus, 0, len(p.running))
+	for record := range p.running {
+		running = append(running, record)
+	}
+	p.wakeup.Broadcast()
+	p.mutex.Unlock()
+
+	for _, item := range queued {
+		item.stopWatch()
+		item.record.cancelFunc()
+		item.record.finish(StateFailed, nil, ErrPoolClosed)
+	}
+	for _, record := range running {
+		record.Cancel()
+	}
+}
+
+// next blocks until there is a task to run, returns nil once pool is closed and queue is empty,
+// or the worker has been idle for IdleTimeout and pool has more than MinWorkers. worker should exit on nil.
+func (p *Pool) next() *poolItem {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	idleSince := time.Now()
+	for p.queue.Len() == 0 || p.paused {
+		if p.closed && p.queue.Len() == 0 {
+			p.workers--
+			return nil
+		}
+
+		var timer *time.Timer
+		if p.options.IdleTimeout > 0 && p.workers > p.options.MinWorkers {
+			idle := time.Since(idleSince)
+			if idle >= p.options.IdleTimeout {
+				p.workers--
+				return nil
+			}
+			timer = time.AfterFunc(p.options.IdleTimeout-idle, func() {
+				p.mutex.Lock()
+				defer p.mutex.Unlock()
+				p.wakeup.Broadcast()
+			})
+		}
+
+		p.idle++
+		p.wakeup.Wait()
+		p.idle--
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	return p.queue.pop()
+}
+
+// poolItem is a queued task.
+type poolItem struct {
+	ctx         context.Context
+	record      *TaskStatus
+	task        AsyncFunc
+	deadline    time.Time
+	hasDeadline bool
+	seq         uint64
+	stopWatch   func() bool
+}
+
+// ready tells whether the task should run, task past its deadline is failed here.
+func (item *poolItem) ready() bool {
+	if !item.stopWatch() {
+		// task was failed by context while queued.
+		return false
+	}
+	if item.record.State().IsTerminalState() {
+		// canceled while queued.
+		return false
+	}
+	if item.hasDeadline && !time.Now().Before(item.deadline) {
+		item.record.finish(StateFailed, nil, ErrDeadlineBeforeStart)
+		return false
+	}
+	return true
+}
+
+// dispatchQueue holds queued tasks, pop returns the next task to run.
+type dispatchQueue interface {
+	Len() int
+	push(item *poolItem)
+	pop() *poolItem
+	each(f func(item *poolItem))
+}
+
+// poolQueue is a heap of poolItem, earliest deadline first, then by submission order.
+type poolQueue []*poolItem
+
+func (q poolQueue) Len() int { return len(q) }
+
+func (q poolQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if a.hasDeadline != b.hasDeadline {
+		return a.hasDeadline
+	}
+	if a.hasDeadline && !a.deadline.Equal(b.deadline) {
+		return a.deadline.Before(b.deadline)
+	}
+	return a.seq < b.seq
+}
+
+func (q poolQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *poolQueue) Push(x interface{}) {
+	*q = append(*q, x.(*poolItem))
+}
+
+func (q *poolQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+func (q *poolQueue) push(item *poolItem) {
+	heap.Push(q, item)
+}
+
+func (q *poolQueue) pop() *poolItem {
+	return heap.Pop(q).(*poolItem)
+}
+
+func (q *poolQueue) each(f func(item *poolItem)) {
+	for _, item := range *q {
+		f(item)
+	}
+}
+
+// fairQueue keeps a poolQueue per tenant, and pops from tenants in turn.
+type fairQueue struct {
+	label   string
+	tenants map[string]*poolQueue
+	// ring is tenants having queued tasks, in the order they get a turn.
+	ring   []string
+	cursor int
+	len    int
+}
+
+func newFairQueue(label string) *fairQueue {
+	return &fairQueue{
+		label:   label,
+		tenants: map[string]*poolQueue{},
+	}
+}
+
+func (q *fairQueue) Len() int { return q.len }
+
+func (q *fairQueue) push(item *poolItem) {
+	tenant := item.record.options.labels[q.label]
+	queue, ok := q.tenants[tenant]
+	if !ok {
+		queue = &poolQueue{}
+		q.tenants[tenant] = queue
+		q.ring = append(q.ring, tenant)
+	}
+	queue.push(item)
+	q.len++
+}
+
+func (q *fairQueue) pop() *poolItem {
+	tenant := q.ring[q.cursor]
+	queue := q.tenants[tenant]
+	item := queue.pop()
+	q.len--
+
+	if queue.Len() == 0 {
+		// next tenant shifts into the cursor.
+		delete(q.tenants, tenant)
+		q.ring = append(q.ring[:q.cursor], q.ring[q.cursor+1:]...)
+	} else {
+		q.cursor++
+	}
+	if q.cursor >= len(q.ring) {
+		q.cursor = 0
+	}
+	return item
+}
+
+func (q *fairQueue) each(f func(item *poolItem)) {
+	for _, queue := range q.tenants {
+		queue.each(f)
+	}
+}
@@ -0,0 +1,115 @@
+package asynctask
+
+import (
+	"sort"
+	"time"
+)
+
+// waitWindowSize is number of recent queue waits kept per tenant for percentiles.
+const waitWindowSize = 1024
+
+// QueueWaitStats summarize how long recently started tasks waited in queue.
+type QueueWaitStats struct {
+	// Count is number of started tasks, percentiles are over the most recent ones (up to 1024).
+	Count uint64
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// waitWindow keeps most recent queue waits (or other durations) in a ring.
+type waitWindow struct {
+	samples []time.Duration
+	next    int
+	count   uint64
+}
+
+func (w *waitWindow) add(wait time.Duration) {
+	w.count++
+	if len(w.samples) < waitWindowSize {
+		w.samples = append(w.samples, wait)
+		return
+	}
+	w.samples[w.next] = wait
+	w.next = (w.next + 1) % waitWindowSize
+}
+
+// sorted returns a sorted copy of samples.
+func (w *waitWindow) sorted() []time.Duration {
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// percentile returns the p-th percentile (between 0 and 100) of samples, there should be some.
+func (w *waitWindow) percentile(p float64) time.Duration {
+	return percentileOf(w.sorted(), p)
+}
+
+func (w *waitWindow) stats() QueueWaitStats {
+	sorted := w.sorted()
+	return QueueWaitStats{
+		Count: w.count,
+		P50:   percentileOf(sorted, 50),
+		P90:   percentileOf(sorted, 90),
+		P99:   percentileOf(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentileOf returns the p-th percentile (between 0 and 100) of sorted, which isn't empty.
+func percentileOf(sorted []time.Duration, p float64) time.Duration {
+	return sorted[int(float64(len(sorted)-1)*p/100)]
+}
+
+// recordWait record queue wait of a started task, by tenant and by priority, mutex should be held.
+func (p *Pool) recordWait(item *poolItem, wait time.Duration) {
+	labels := item.record.options.labels
+	p.waits = addWait(p.waits, labels[p.options.TenantLabel], wait)
+	p.priorityWaits = addWait(p.priorityWaits, labels[p.options.PriorityLabel], wait)
+}
+
+// addWait adds wait to window of key in windows, created if needed, and returns windows.
+func addWait(windows map[string]*waitWindow, key string, wait time.Duration) map[string]*waitWindow {
+	if windows == nil {
+		windows = map[string]*waitWindow{}
+	}
+	window, ok := windows[key]
+	if !ok {
+		window = &waitWindow{}
+		windows[key] = window
+	}
+	window.add(wait)
+	return windows
+}
+
+// queued returns number of tasks waiting in queue, ones which finished there (e.g. canceled) are not counted,
+// mutex should be held.
+func (p *Pool) queued() int {
+	queued := 0
+	p.queue.each(func(item *poolItem) {
+		if !item.record.State().IsTerminalState() {
+			queued++
+		}
+	})
+	return queued
+}
+
+// oldestQueued returns age of the task waiting longest in queue, ones which finished there are skipped,
+// mutex should be held.
+func (p *Pool) oldestQueued() time.Duration {
+	var oldest time.Time
+	p.queue.each(func(item *poolItem) {
+		if item.record.State().IsTerminalState() {
+			return
+		}
+		if oldest.IsZero() || item.record.createdAt.Before(oldest) {
+			oldest = item.record.createdAt
+		}
+	})
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
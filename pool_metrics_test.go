@@ -0,0 +1,69 @@
+package asynctask_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolQueueWaitStats(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, TenantLabel: "tenant"})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+
+	tasks := []*asynctask.TaskStatus{
+		pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("tenant", "starved")),
+		pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("tenant", "starved")),
+	}
+	time.Sleep(30 * time.Millisecond)
+	stats := pool.Stats()
+	assert.True(t, stats.OldestQueued >= 30*time.Millisecond, "oldest queued task age")
+
+	release()
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tasks...))
+	stats = pool.Stats()
+	assert.Equal(t, time.Duration(0), stats.OldestQueued)
+	starved := stats.QueueWait["starved"]
+	assert.Equal(t, uint64(2), starved.Count)
+	assert.True(t, starved.P50 >= 30*time.Millisecond, "starved tenant waited")
+	assert.True(t, starved.P50 <= starved.P99 && starved.P99 <= starved.Max)
+	// blocking task is unlabeled, and started right away.
+	assert.Equal(t, uint64(1), stats.QueueWait[""].Count)
+	assert.True(t, stats.QueueWait[""].Max < starved.P50)
+}
+
+func TestPoolQueueWaitByPriority(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, PriorityLabel: "priority"})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+
+	low := pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("priority", "low"))
+	canceled := pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("priority", "low"))
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, 2, pool.Stats().Queued)
+
+	// task canceled in queue is not waiting anymore.
+	canceled.Cancel()
+	high := pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithLabel("priority", "high"))
+	stats := pool.Stats()
+	assert.Equal(t, 2, stats.Queued)
+	assert.True(t, stats.OldestQueued >= 30*time.Millisecond, "oldest queued task age")
+
+	release()
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, low, high))
+	stats = pool.Stats()
+	assert.Equal(t, uint64(1), stats.QueueWaitByPriority["low"].Count)
+	assert.Equal(t, uint64(1), stats.QueueWaitByPriority["high"].Count)
+	assert.True(t, stats.QueueWaitByPriority["low"].P50 >= 30*time.Millisecond, "low priority task waited")
+	assert.True(t, stats.QueueWaitByPriority["high"].P50 < stats.QueueWaitByPriority["low"].P50)
+}
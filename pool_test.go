@@ -0,0 +1,347 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// getRecordingTask append name to order when it runs.
+func getRecordingTask(name string, order *[]string, mutex *sync.Mutex) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		*order = append(*order, name)
+		return name, nil
+	}
+}
+
+// blockPool occupy all workers of the pool until returned func is called.
+func blockPool(ctx context.Context, pool *asynctask.Pool, workers int) func() {
+	release := make(chan struct{})
+	started := sync.WaitGroup{}
+	started.Add(workers)
+	for i := 0; i < workers; i++ {
+		pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+			started.Done()
+			<-release
+			return nil, nil
+		})
+	}
+	started.Wait()
+	return func() { close(release) }
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 2})
+	tasks := make([]*asynctask.TaskStatus, 0, 10)
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, pool.Submit(ctx, getCountingTask(3, time.Millisecond)))
+	}
+
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tasks...))
+	for _, tsk := range tasks {
+		result, err := tsk.Wait(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result)
+	}
+
+	pool.Close()
+	tsk := pool.Submit(ctx, getCountingTask(3, time.Millisecond))
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrPoolClosed, err)
+}
+
+func TestPoolEarliestDeadlineFirst(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+
+	mutex := sync.Mutex{}
+	var order []string
+	noDeadline := pool.Submit(ctx, getRecordingTask("none", &order, &mutex))
+	lateCtx, cancelLate := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelLate()
+	late := pool.Submit(lateCtx, getRecordingTask("late", &order, &mutex))
+	earlyCtx, cancelEarly := context.WithTimeout(ctx, time.Second)
+	defer cancelEarly()
+	early := pool.Submit(earlyCtx, getRecordingTask("early", &order, &mutex))
+	canceled := pool.Submit(ctx, getRecordingTask("canceled", &order, &mutex))
+	assert.Equal(t, asynctask.StateQueued, canceled.State())
+	canceled.Cancel()
+	assert.Equal(t, 4, pool.Len())
+
+	release()
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, noDeadline, late, early))
+	assert.Equal(t, []string{"early", "late", "none"}, order)
+	assert.Equal(t, asynctask.StateCanceled, canceled.State())
+}
+
+func TestPoolDeadlineBeforeStart(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+	defer release()
+
+	mutex := sync.Mutex{}
+	var order []string
+	doomedCtx, cancelDoomed := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelDoomed()
+	doomed := pool.Submit(doomedCtx, getRecordingTask("doomed", &order, &mutex))
+
+	// fail while still queued, without waiting for a worker.
+	_, err := doomed.Wait(ctx)
+	assert.Equal(t, asynctask.ErrDeadlineBeforeStart, err)
+	assert.Equal(t, asynctask.StateFailed, doomed.State())
+
+	// parent canceled while queued.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	tsk := pool.Submit(canceledCtx, getRecordingTask("canceled", &order, &mutex))
+	cancel()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Empty(t, order)
+}
+
+func TestPoolStats(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+
+	tsk := pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	})
+	stats := pool.Stats()
+	assert.Equal(t, 1, stats.Queued)
+	assert.Equal(t, 1, stats.Running)
+
+	time.Sleep(30 * time.Millisecond)
+	release()
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+
+	info := tsk.Info()
+	assert.True(t, info.QueueDuration() >= 30*time.Millisecond, "task waited for the blocking task")
+	assert.True(t, info.Duration() >= 20*time.Millisecond)
+
+	assert.Eventually(t, func() bool { return pool.Stats().Finished == 2 }, time.Second, time.Millisecond)
+	stats = pool.Stats()
+	assert.Equal(t, 0, stats.Queued)
+	assert.Equal(t, 0, stats.Running)
+	assert.Equal(t, uint64(2), stats.Started)
+	assert.True(t, stats.QueueTime >= 30*time.Millisecond)
+	assert.True(t, stats.ExecutionTime >= 50*time.Millisecond)
+}
+
+func TestPoolPanicAsError(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+
+	_, err := pool.Submit(ctx, getPanicTask(0)).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+
+	result, err := pool.Submit(ctx, getCountingTask(3, time.Millisecond)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Panics)
+	assert.Equal(t, uint64(0), stats.WorkerRestarts)
+}
+
+func TestPoolPanicRestartWorker(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, PanicPolicy: asynctask.PanicRestartWorker})
+
+	_, err := pool.Submit(ctx, getPanicTask(0)).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+
+	// replacement worker picks up next task.
+	result, err := pool.Submit(ctx, getCountingTask(3, time.Millisecond)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+
+	pool.Close()
+	stats := pool.Stats()
+	assert.Equal(t, uint64(1), stats.Panics)
+	assert.Equal(t, uint64(1), stats.WorkerRestarts)
+}
+
+func TestPoolPanicShutdownPool(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 2, PanicPolicy: asynctask.PanicShutdownPool})
+
+	running := pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	for running.State() != asynctask.StateRunning {
+		time.Sleep(time.Millisecond)
+	}
+
+	release := make(chan struct{})
+	panicking := pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		<-release
+		panic("invariant broken")
+	})
+	queued := pool.Submit(ctx, getCountingTask(3, time.Millisecond))
+	close(release)
+
+	_, err := panicking.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+
+	_, err = running.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, asynctask.StateCanceled, running.State())
+
+	_, err = queued.Wait(ctx)
+	assert.Equal(t, asynctask.ErrPoolClosed, err)
+	assert.Equal(t, asynctask.StateFailed, queued.State())
+
+	pool.Close()
+	_, err = pool.Submit(ctx, getCountingTask(3, time.Millisecond)).Wait(ctx)
+	assert.Equal(t, asynctask.ErrPoolClosed, err)
+}
+
+func TestPoolElastic(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 4, MinWorkers: 1, IdleTimeout: 30 * time.Millisecond})
+	defer pool.Close()
+	assert.Equal(t, 1, pool.Stats().Workers)
+
+	// grow on demand.
+	release := blockPool(ctx, pool, 4)
+	stats := pool.Stats()
+	assert.Equal(t, 4, stats.Workers)
+	assert.Equal(t, 4, stats.Running)
+
+	// capped at Workers.
+	queued := pool.Submit(ctx, getCountingTask(3, time.Millisecond))
+	assert.Equal(t, 4, pool.Stats().Workers)
+	release()
+	_, err := queued.Wait(ctx)
+	assert.NoError(t, err)
+
+	// shrink back to MinWorkers once idle.
+	assert.Eventually(t, func() bool { return pool.Stats().Workers == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, pool.Stats().IdleWorkers)
+
+	// and grow again.
+	release = blockPool(ctx, pool, 2)
+	assert.Equal(t, 2, pool.Stats().Workers)
+	release()
+}
+
+func TestPoolFixedWorkers(t *testing.T) {
+	t.Parallel()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 3, IdleTimeout: time.Millisecond})
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 3, pool.Stats().Workers, "MinWorkers default to Workers")
+	pool.Close()
+	assert.Equal(t, 0, pool.Stats().Workers)
+}
+
+func TestPoolPauseResume(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 2})
+	release := blockPool(ctx, pool, 1)
+
+	pool.Pause()
+	assert.True(t, pool.Paused())
+	order := []string{}
+	mutex := sync.Mutex{}
+	tasks := []*asynctask.TaskStatus{
+		pool.Submit(ctx, getRecordingTask("a", &order, &mutex)),
+		pool.Submit(ctx, getRecordingTask("b", &order, &mutex)),
+	}
+	canceled := pool.Submit(ctx, getRecordingTask("c", &order, &mutex))
+
+	// running task continues, queued ones wait.
+	release()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 3, pool.Len())
+	assert.Equal(t, asynctask.StateQueued, tasks[0].State())
+	canceled.Cancel()
+
+	pool.Resume()
+	assert.False(t, pool.Paused())
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tasks...))
+	assert.Equal(t, []string{"a", "b"}, order)
+
+	// close drains a paused pool.
+	pool.Pause()
+	tsk := pool.Submit(ctx, getCountingTask(3, time.Millisecond))
+	pool.Close()
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+}
+
+func TestPoolTenantFairness(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1, TenantLabel: "tenant"})
+	release := blockPool(ctx, pool, 1)
+
+	order := []string{}
+	mutex := sync.Mutex{}
+	var tasks []*asynctask.TaskStatus
+	// noisy tenant submits first.
+	for _, name := range []string{"noisy-1", "noisy-2", "noisy-3", "noisy-4"} {
+		tasks = append(tasks, pool.Submit(ctx, getRecordingTask(name, &order, &mutex), asynctask.WithLabel("tenant", "noisy")))
+	}
+	tasks = append(tasks,
+		pool.Submit(ctx, getRecordingTask("quiet-1", &order, &mutex), asynctask.WithLabel("tenant", "quiet")),
+		pool.Submit(ctx, getRecordingTask("quiet-2", &order, &mutex), asynctask.WithLabel("tenant", "quiet")),
+		pool.Submit(ctx, getRecordingTask("unlabeled", &order, &mutex)),
+	)
+
+	release()
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tasks...))
+	assert.Equal(t, []string{"noisy-1", "quiet-1", "unlabeled", "noisy-2", "quiet-2", "noisy-3", "noisy-4"}, order)
+	pool.Close()
+}
@@ -0,0 +1,205 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// defaultMaxOutput is how much of stdout and stderr a process task captures by default.
+const defaultMaxOutput = 1 << 20
+
+// ProcessResult is the outcome of an external process, see StartProcess.
+type ProcessResult struct {
+	// ExitCode is -1 if the process didn't exit on its own, e.g. it was killed.
+	ExitCode int
+	// Stdout and Stderr are captured output, up to the limit, see WithOutputLimit.
+	Stdout          []byte
+	Stderr          []byte
+	StdoutTruncated bool
+	StderrTruncated bool
+}
+
+// ExitCodeError is the error of a process task which exited with a non-zero code, see WithExitCodes.
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("process exited with code %d", e.Code)
+}
+
+// processOptions holds configuration of a process task.
+type processOptions struct {
+	exitCodes   func(code int) error
+	maxOutput   int
+	gracePeriod time.Duration
+}
+
+func (o *taskOptions) processOptions() *processOptions {
+	if o.process == nil {
+		o.process = &processOptions{maxOutput: defaultMaxOutput}
+	}
+	return o.process
+}
+
+// WithExitCodes maps the exit code of a process task to its error, e.g. to accept code 2 of a diff tool,
+// by default any non-zero code fails the task with ExitCodeError.
+func WithExitCodes(mapper func(code int) error) TaskOption {
+	return func(o *taskOptions) {
+		o.processOptions().exitCodes = mapper
+	}
+}
+
+// WithOutputLimit caps how much of stdout and stderr a process task captures, each, default to 1 MiB.
+// output past the limit is discarded, not blocking the process.
+func WithOutputLimit(maxBytes int) TaskOption {
+	return func(o *taskOptions) {
+		o.processOptions().maxOutput = maxBytes
+	}
+}
+
+// WithKillGracePeriod gives a canceled process task time to exit after an interrupt, before it's killed,
+// by default it's killed right away. processes are always killed right away on windows.
+func WithKillGracePeriod(d time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.processOptions().gracePeriod = d
+	}
+}
+
+// ProcessTask is a handle to a task running an external process.
+type ProcessTask struct {
+	*TaskStatus
+}
+
+// Result block until the process exited, and returns its outcome, which is set even if the task failed on exit code.
+func (p *ProcessTask) Result(ctx context.Context) (*ProcessResult, error) {
+	result, err := p.Wait(ctx)
+	processResult, _ := result.(*ProcessResult)
+	return processResult, err
+}
+
+// StartProcess run the command as a task, it's killed if the task is canceled or ctx is done, see WithKillGracePeriod.
+// stdout and stderr are captured in ProcessResult unless cmd already has them set, see WithOutputLimit.
+// task fails if the process can't start, or exits with non-zero code, see WithExitCodes.
+// a command runs only once, WithRetry is ignored, use StartProcessFunc to retry.
+func StartProcess(ctx context.Context, cmd *exec.Cmd, opts ...TaskOption) *ProcessTask {
+	opts = append(opts[:len(opts):len(opts)], func(o *taskOptions) {
+		o.retry = nil
+	})
+	return StartProcessFunc(ctx, func() *exec.Cmd { return cmd }, opts...)
+}
+
+// StartProcessFunc is StartProcess with a command built by newCmd for each attempt, so the task can be retried, see WithRetry.
+func StartProcessFunc(ctx context.Context, newCmd func() *exec.Cmd, opts ...TaskOption) *ProcessTask {
+	options := newTaskOptions(opts)
+	process := options.processOptions()
+	return &ProcessTask{TaskStatus: Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return runProcess(ctx, newCmd(), process)
+	}, opts...)}
+}
+
+func runProcess(ctx context.Context, cmd *exec.Cmd, options *processOptions) (*ProcessResult, error) {
+	var stdout, stderr *limitedBuffer
+	if cmd.Stdout == nil {
+		stdout = &limitedBuffer{limit: options.maxOutput}
+		cmd.Stdout = stdout
+	}
+	if cmd.Stderr == nil {
+		stderr = &limitedBuffer{limit: options.maxOutput}
+		cmd.Stderr = stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-exited:
+		case <-ctx.Done():
+			killProcess(cmd.Process, options.gracePeriod, exited)
+		}
+	}()
+	waitErr := cmd.Wait()
+	close(exited)
+	<-stopped
+
+	result := &ProcessResult{ExitCode: cmd.ProcessState.ExitCode()}
+	if stdout != nil {
+		result.Stdout, result.StdoutTruncated = stdout.bytes()
+	}
+	if stderr != nil {
+		result.Stderr, result.StderrTruncated = stderr.bytes()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	case waitErr != nil && !errors.As(waitErr, &exitErr):
+		// e.g. copying output failed.
+		return result, waitErr
+	case result.ExitCode == -1:
+		return result, waitErr
+	case options.exitCodes != nil:
+		return result, options.exitCodes(result.ExitCode)
+	case result.ExitCode != 0:
+		return result, &ExitCodeError{Code: result.ExitCode}
+	default:
+		return result, nil
+	}
+}
+
+// killProcess interrupts the process, and kills it if it didn't exit within grace.
+func killProcess(process *os.Process, grace time.Duration, exited <-chan struct{}) {
+	if grace > 0 && runtime.GOOS != "windows" {
+		if process.Signal(os.Interrupt) == nil {
+			select {
+			case <-exited:
+				return
+			case <-time.After(grace):
+			}
+		}
+	}
+	_ = process.Kill()
+}
+
+// limitedBuffer keeps the first limit bytes written to it, and discards the rest.
+type limitedBuffer struct {
+	mutex     sync.Mutex
+	limit     int
+	buf       []byte
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	room := b.limit - len(b.buf)
+	if room < 0 {
+		room = 0
+	}
+	if len(p) > room {
+		b.truncated = true
+		b.buf = append(b.buf, p[:room]...)
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	// report everything written, so the process isn't failed on a short write.
+	return len(p), nil
+}
+
+func (b *limitedBuffer) bytes() ([]byte, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf, b.truncated
+}
@@ -0,0 +1,126 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func skipWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+}
+
+func TestStartProcess(t *testing.T) {
+	t.Parallel()
+	skipWithoutShell(t)
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	result, err := asynctask.StartProcess(ctx, exec.Command("sh", "-c", "echo hello; echo oops >&2")).Result(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "hello\n", string(result.Stdout))
+	assert.Equal(t, "oops\n", string(result.Stderr))
+
+	// non-zero exit code fails the task, output is still there.
+	tsk := asynctask.StartProcess(ctx, exec.Command("sh", "-c", "echo partial; exit 3"))
+	result, err = tsk.Result(ctx)
+	var exitErr *asynctask.ExitCodeError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.Code)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "partial\n", string(result.Stdout))
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// mapped exit code.
+	result, err = asynctask.StartProcess(ctx, exec.Command("sh", "-c", "exit 2"), asynctask.WithExitCodes(func(code int) error {
+		if code == 2 {
+			return nil
+		}
+		return &asynctask.ExitCodeError{Code: code}
+	})).Result(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.ExitCode)
+
+	// capture limit.
+	result, err = asynctask.StartProcess(ctx, exec.Command("sh", "-c", "echo 0123456789"), asynctask.WithOutputLimit(4)).Result(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123", string(result.Stdout))
+	assert.True(t, result.StdoutTruncated)
+	assert.False(t, result.StderrTruncated)
+
+	// can't start.
+	_, err = asynctask.StartProcess(ctx, exec.Command("/no/such/binary")).Result(ctx)
+	assert.Error(t, err)
+}
+
+func TestStartProcessRetry(t *testing.T) {
+	t.Parallel()
+	skipWithoutShell(t)
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// a command runs once, the real exit code error is kept.
+	retry := asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond})
+	tsk := asynctask.StartProcess(ctx, exec.Command("sh", "-c", "exit 3"), retry)
+	_, err := tsk.Result(ctx)
+	var exitErr *asynctask.ExitCodeError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 1, tsk.Info().Attempt)
+
+	// a command built for each attempt is retried.
+	attempts := 0
+	result, err := asynctask.StartProcessFunc(ctx, func() *exec.Cmd {
+		attempts++
+		return exec.Command("sh", "-c", fmt.Sprintf("exit $((2 - %d))", attempts))
+	}, retry).Result(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestStartProcessCancel(t *testing.T) {
+	t.Parallel()
+	skipWithoutShell(t)
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// killed right away by default.
+	tsk := asynctask.StartProcess(ctx, exec.Command("sleep", "10"))
+	time.Sleep(20 * time.Millisecond)
+	tsk.Cancel()
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+
+	// interrupted first, process cleans up within grace.
+	procCtx, cancelProc := context.WithCancel(ctx)
+	graceful := asynctask.StartProcess(procCtx, exec.Command("sh", "-c", "trap 'echo bye; exit 7' INT; while :; do sleep 0.01; done"),
+		asynctask.WithKillGracePeriod(time.Second))
+	time.Sleep(50 * time.Millisecond)
+	cancelProc()
+	result, err := graceful.Result(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 7, result.ExitCode)
+	assert.Equal(t, "bye\n", string(result.Stdout))
+
+	// ignores the interrupt, killed once grace passed.
+	procCtx, cancelProc = context.WithCancel(ctx)
+	stubborn := asynctask.StartProcess(procCtx, exec.Command("sh", "-c", "trap '' INT; while :; do :; done"),
+		asynctask.WithKillGracePeriod(50*time.Millisecond))
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	cancelProc()
+	result, err = stubborn.Result(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, -1, result.ExitCode)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+}
@@ -0,0 +1,201 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDrainTimeout is returned if tasks didn't finish within DrainOptions.DrainTimeout
+var ErrDrainTimeout = errors.New("drain timeout")
+
+// Registry keeps track of running tasks, so they can be found and drained without holding every handle.
+// tasks are dropped from the registry once they finish.
+type Registry struct {
+	mutex sync.Mutex
+	tasks map[*TaskStatus]struct{}
+	// durations are recent durations of completed tasks by name, see SuggestedTimeout.
+	durations map[string]*waitWindow
+	// slos are objectives of task names with their recent outcomes, see SetSLO.
+	slos         map[string]*sloWindow
+	sloCallbacks []func(SLOStatus)
+}
+
+// defaultRegistryHolder keeps atomic.Value storing same concrete type.
+type defaultRegistryHolder struct {
+	registry *Registry
+}
+
+var defaultRegistry atomic.Value
+
+// SetDefaultRegistry sets the registry every task created afterwards is tracked in, nil to stop tracking.
+func SetDefaultRegistry(r *Registry) {
+	defaultRegistry.Store(defaultRegistryHolder{registry: r})
+}
+
+// DefaultRegistry returns the registry set by SetDefaultRegistry, nil if not set.
+func DefaultRegistry() *Registry {
+	holder, _ := defaultRegistry.Load().(defaultRegistryHolder)
+	return holder.registry
+}
+
+// trackDefault add the task to default registry, if set.
+func (t *TaskStatus) trackDefault() {
+	if r := DefaultRegistry(); r != nil {
+		r.Track(t)
+	}
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		tasks: map[*TaskStatus]struct{}{},
+	}
+}
+
+// Track add tasks to the registry, finished tasks are ignored.
+func (r *Registry) Track(tasks ...*TaskStatus) {
+	for _, tsk := range tasks {
+		tsk := tsk
+		r.mutex.Lock()
+		r.tasks[tsk] = struct{}{}
+		r.mutex.Unlock()
+
+		tsk.onDone(func() {
+			info := tsk.Info()
+			r.mutex.Lock()
+			delete(r.tasks, tsk)
+			if info.Name != "" && info.State == StateCompleted {
+				r.recordDuration(info.Name, info.Duration())
+			}
+			callbacks, status := r.recordSLO(info)
+			r.mutex.Unlock()
+
+			for _, callback := range callbacks {
+				callback(status)
+			}
+		})
+	}
+}
+
+// Tasks returns tasks in the registry which are not yet finished.
+func (r *Registry) Tasks() []*TaskStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tasks := make([]*TaskStatus, 0, len(r.tasks))
+	for tsk := range r.tasks {
+		tasks = append(tasks, tsk)
+	}
+	return tasks
+}
+
+// recordDuration record duration of a completed task, mutex should be held.
+func (r *Registry) recordDuration(name string, d time.Duration) {
+	if r.durations == nil {
+		r.durations = map[string]*waitWindow{}
+	}
+	window, ok := r.durations[name]
+	if !ok {
+		window = &waitWindow{}
+		r.durations[name] = window
+	}
+	window.add(d)
+}
+
+// SuggestedTimeout returns the percentile (between 0 and 100, e.g. 99.9) of how long recent tasks named name ran,
+// over the last 1024 which completed while tracked, for a data-driven WithTimeout rather than a guess; add some headroom.
+// ok is false if no such task completed yet.
+func (r *Registry) SuggestedTimeout(name string, percentile float64) (timeout time.Duration, ok bool) {
+	if percentile < 0 {
+		percentile = 0
+	} else if percentile > 100 {
+		percentile = 100
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	window, ok := r.durations[name]
+	if !ok {
+		return 0, false
+	}
+	return window.percentile(percentile), true
+}
+
+// Len returns number of tasks in the registry which are not yet finished.
+func (r *Registry) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.tasks)
+}
+
+// CancelWhere cancel tracked tasks matching the predicate, returns number of tasks canceled.
+func (r *Registry) CancelWhere(predicate func(TaskInfo) bool) int {
+	canceled := 0
+	for _, tsk := range r.Tasks() {
+		if predicate(tsk.Info()) {
+			tsk.Cancel()
+			canceled++
+		}
+	}
+	return canceled
+}
+
+// CancelByLabel cancel tracked tasks having the label, returns number of tasks canceled.
+func (r *Registry) CancelByLabel(key, value string) int {
+	return r.CancelWhere(func(info TaskInfo) bool {
+		labelValue, ok := info.Labels[key]
+		return ok && labelValue == value
+	})
+}
+
+// DrainOptions defines options for Registry.Drain function
+type DrainOptions struct {
+	// DrainTimeout limits how long to wait for tasks to finish, zero means no limit (only ctx).
+	// tasks still running after the timeout get canceled.
+	DrainTimeout time.Duration
+}
+
+// Drain block current thread/routine until all tracked tasks finished.
+// tasks still running when DrainTimeout passed or ctx is done get canceled, and an error is returned.
+// errors from tasks themselves are not reported, check on individual tasks for that.
+// tasks tracked after Drain started are not waited.
+func (r *Registry) Drain(ctx context.Context, options *DrainOptions) error {
+	if options == nil {
+		options = &DrainOptions{}
+	}
+
+	waitCtx := ctx
+	if options.DrainTimeout > 0 {
+		var cancelFunc context.CancelFunc
+		waitCtx, cancelFunc = context.WithTimeout(ctx, options.DrainTimeout)
+		defer cancelFunc()
+	}
+
+	tasks := r.Tasks()
+	for _, tsk := range tasks {
+		if _, err := tsk.waitFinished(waitCtx); err != nil && waitCtx.Err() != nil {
+			break
+		}
+	}
+
+	if waitCtx.Err() == nil {
+		return nil
+	}
+
+	canceled := 0
+	for _, tsk := range tasks {
+		if !tsk.State().IsTerminalState() {
+			tsk.Cancel()
+			canceled++
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("drain canceled, %d tasks canceled: %w", canceled, ctx.Err())
+	}
+	return fmt.Errorf("%d tasks canceled: %w", canceled, ErrDrainTimeout)
+}
@@ -0,0 +1,150 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryDrain(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	t1 := asynctask.Start(ctx, getCountingTask(10, 2*time.Millisecond))
+	t2 := asynctask.Start(ctx, getCountingTask(10, 3*time.Millisecond))
+	registry.Track(t1, t2, asynctask.NewCompletedTask())
+	assert.Equal(t, 2, registry.Len(), "completed task shouldn't be tracked")
+
+	err := registry.Drain(ctx, &asynctask.DrainOptions{DrainTimeout: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.StateCompleted, t1.State())
+	assert.Equal(t, asynctask.StateCompleted, t2.State())
+	assert.Equal(t, 0, registry.Len())
+	assert.Empty(t, registry.Tasks())
+}
+
+func TestRegistryDrainTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	fast := asynctask.Start(ctx, getCountingTask(1, time.Millisecond))
+	slow := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	registry.Track(fast, slow)
+
+	err := registry.Drain(ctx, &asynctask.DrainOptions{DrainTimeout: 20 * time.Millisecond})
+	assert.True(t, errors.Is(err, asynctask.ErrDrainTimeout))
+	assert.Equal(t, "1 tasks canceled: drain timeout", err.Error())
+	assert.Equal(t, asynctask.StateCompleted, fast.State())
+	assert.Equal(t, asynctask.StateCanceled, slow.State())
+
+	// canceled context also cancel remaining tasks.
+	slow = asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	registry.Track(slow)
+	drainCtx, cancelDrain := context.WithCancel(ctx)
+	cancelDrain()
+	err = registry.Drain(drainCtx, nil)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, asynctask.StateCanceled, slow.State())
+}
+
+func TestRegistryCancelByLabel(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	exportA := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond), asynctask.WithName("export"), asynctask.WithLabel("tenant", "a"))
+	importA := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond), asynctask.WithName("import"), asynctask.WithLabel("tenant", "a"))
+	exportB := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond), asynctask.WithName("export"), asynctask.WithLabel("tenant", "b"))
+	registry.Track(exportA, importA, exportB)
+
+	canceled := registry.CancelWhere(func(info asynctask.TaskInfo) bool {
+		return info.Name == "export" && info.Labels["tenant"] == "a"
+	})
+	assert.Equal(t, 1, canceled)
+	assert.Equal(t, asynctask.StateCanceled, exportA.State())
+	assert.Equal(t, asynctask.StateRunning, importA.State())
+
+	assert.Equal(t, 1, registry.CancelByLabel("tenant", "a"))
+	assert.Equal(t, asynctask.StateCanceled, importA.State())
+	assert.Equal(t, 0, registry.CancelByLabel("tenant", "c"))
+	assert.Equal(t, asynctask.StateRunning, exportB.State())
+
+	assert.Equal(t, map[string]string{"tenant": "b"}, exportB.Info().Labels)
+	exportB.Cancel()
+}
+
+// not parallel, it changes default registry.
+func TestDefaultRegistry(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	asynctask.SetDefaultRegistry(registry)
+	defer asynctask.SetDefaultRegistry(nil)
+	assert.Equal(t, registry, asynctask.DefaultRegistry())
+
+	pool := asynctask.NewPool(nil)
+	defer pool.Close()
+	tasks := []*asynctask.TaskStatus{
+		asynctask.Start(ctx, getCountingTask(10, 100*time.Millisecond)),
+		asynctask.StartAfter(ctx, time.Second, getCountingTask(3, time.Millisecond)),
+		pool.Submit(ctx, getCountingTask(10, 100*time.Millisecond)),
+	}
+	tasks = append(tasks, tasks[0].ContinueWith(ctx, func(context.Context, interface{}) (interface{}, error) { return nil, nil }))
+	assert.Equal(t, 4, registry.Len())
+
+	for _, tsk := range tasks {
+		tsk.Cancel()
+	}
+	assert.Equal(t, 0, registry.Len())
+
+	asynctask.SetDefaultRegistry(nil)
+	asynctask.Start(ctx, getCountingTask(3, time.Millisecond))
+	assert.Equal(t, 0, registry.Len())
+}
+
+func TestRegistrySuggestedTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	_, ok := registry.SuggestedTimeout("resize", 99)
+	assert.False(t, ok)
+
+	var tasks []*asynctask.TaskStatus
+	for i := 1; i <= 10; i++ {
+		tasks = append(tasks, asynctask.Start(ctx, getCountingTask(i, 2*time.Millisecond), asynctask.WithName("resize")))
+	}
+	// failed ones and other names don't count.
+	tasks = append(tasks,
+		asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+			time.Sleep(100 * time.Millisecond)
+			return nil, errors.New("slow failure")
+		}, asynctask.WithName("resize")),
+		asynctask.Start(ctx, getCountingTask(30, 2*time.Millisecond), asynctask.WithName("backup")))
+	registry.Track(tasks...)
+	assert.NoError(t, registry.Drain(ctx, nil))
+	// durations are recorded as tasks leave the registry, right after they finish.
+	assert.Eventually(t, func() bool { return registry.Len() == 0 }, time.Second, time.Millisecond)
+
+	p0, ok := registry.SuggestedTimeout("resize", 0)
+	assert.True(t, ok)
+	p100, _ := registry.SuggestedTimeout("resize", 100)
+	p50, _ := registry.SuggestedTimeout("resize", 50)
+	assert.True(t, p0 <= p50 && p50 <= p100)
+	assert.GreaterOrEqual(t, int64(p100), int64(20*time.Millisecond))
+	assert.Less(t, int64(p100), int64(100*time.Millisecond))
+	backup, ok := registry.SuggestedTimeout("backup", 99.9)
+	assert.True(t, ok)
+	assert.Greater(t, int64(backup), int64(p100))
+}
@@ -0,0 +1,27 @@
+package asynctask
+
+import "errors"
+
+// ErrResultReleased is returned if Wait is called after result of the task was released.
+// keep the result from the first Wait, or don't release it, if you need it more than once.
+var ErrResultReleased = errors.New("result released, it can't be retrieved again")
+
+// Release drops the reference to the task result, so it can be garbage collected while the handle is still around.
+// Wait afterwards returns ErrResultReleased; releasing a running task drops its result once it finishes.
+func (t *TaskStatus) Release() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.released = true
+	t.result = nil
+	if t.state.IsTerminalState() {
+		t.publishOutcome()
+	}
+}
+
+// WithReleaseOnWait release the task result once it's returned from Wait,
+// for large results consumed by a single waiter.
+func WithReleaseOnWait() TaskOption {
+	return func(o *taskOptions) {
+		o.releaseOnWait = true
+	}
+}
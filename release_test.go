@@ -0,0 +1,74 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelease(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(2, time.Millisecond))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	tsk.Release()
+	result, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+	assert.Nil(t, result)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State(), "release doesn't change state")
+
+	// release a running task, result is dropped when it finishes.
+	tsk = asynctask.Start(ctx, getCountingTask(2, 10*time.Millisecond))
+	tsk.Release()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+}
+
+func TestReleaseOnWait(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(2, 10*time.Millisecond), asynctask.WithReleaseOnWait())
+
+	// interrupted wait doesn't consume the result
+	shortCtx, cancelShort := context.WithCancel(ctx)
+	cancelShort()
+	_, err := tsk.Wait(shortCtx)
+	assert.Equal(t, context.Canceled, err)
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	result, err = tsk.WaitWithTimeout(ctx, time.Second)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+	assert.Nil(t, result)
+}
+
+func TestReleaseOnWaitAfterInternalWaits(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// waits made by the library leave the result to the user's own Wait.
+	registry := asynctask.NewRegistry()
+	tsk := asynctask.Start(ctx, getCountingTask(2, 10*time.Millisecond), asynctask.WithReleaseOnWait())
+	registry.Track(tsk)
+	assert.NoError(t, registry.Drain(ctx, &asynctask.DrainOptions{}))
+	assert.NoError(t, asynctask.WaitAll(ctx, &asynctask.WaitAllOptions{}, tsk))
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrResultReleased, err)
+}
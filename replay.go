@@ -0,0 +1,269 @@
+package asynctask
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReplayEventKind is what happened to a task in a replay log.
+type ReplayEventKind string
+
+// ReplayStart is recorded when task function start running.
+const ReplayStart ReplayEventKind = "start"
+
+// ReplayRetry is recorded when task failed an attempt and is about to try again.
+const ReplayRetry ReplayEventKind = "retry"
+
+// ReplayFinish is recorded when task Completed or Failed.
+const ReplayFinish ReplayEventKind = "finish"
+
+// ReplayCancel is recorded when task got canceled.
+const ReplayCancel ReplayEventKind = "cancel"
+
+// ReplayEvent is one line of a replay log.
+type ReplayEvent struct {
+	// Seq is order of the event in the log, starting from 1.
+	Seq int `json:"seq"`
+	// Offset is time since the first event in the log.
+	Offset time.Duration   `json:"offset"`
+	Kind   ReplayEventKind `json:"kind"`
+	// TaskID tells tasks apart in the log, in the order they were first seen, starting from 1.
+	TaskID  int    `json:"taskId"`
+	Name    string `json:"name,omitempty"`
+	Attempt int    `json:"attempt"`
+	State   State  `json:"state"`
+	// Duration is how long the task run, on finish and cancel.
+	Duration time.Duration `json:"duration,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Recorder is an EventSink writing a replay log of tasks, as JSON lines, to reproduce a schedule with Replay.
+// set it with SetEventSink, only tasks started afterwards are recorded.
+type Recorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	start   time.Time
+	seq     int
+	ids     map[*TaskStatus]int
+	lastID  int
+	err     error
+}
+
+// NewRecorder returns a recorder writing replay log to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		encoder: json.NewEncoder(w),
+		ids:     map[*TaskStatus]int{},
+	}
+}
+
+// Err returns first error writing the log, recording stops after that.
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// OnStart implements EventSink.
+func (r *Recorder) OnStart(tsk *TaskStatus) {
+	r.record(tsk, ReplayStart, nil)
+}
+
+// OnFinish implements EventSink.
+func (r *Recorder) OnFinish(tsk *TaskStatus, err error) {
+	r.record(tsk, ReplayFinish, err)
+}
+
+// OnRetry implements EventSink.
+func (r *Recorder) OnRetry(tsk *TaskStatus, attempt int, err error) {
+	r.record(tsk, ReplayRetry, err)
+}
+
+// OnCancel implements EventSink.
+func (r *Recorder) OnCancel(tsk *TaskStatus, err error) {
+	r.record(tsk, ReplayCancel, err)
+}
+
+func (r *Recorder) record(tsk *TaskStatus, kind ReplayEventKind, err error) {
+	info := tsk.Info()
+	now := time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return
+	}
+	if r.seq == 0 {
+		r.start = now
+	}
+
+	id, ok := r.ids[tsk]
+	if !ok {
+		r.lastID++
+		id = r.lastID
+		r.ids[tsk] = id
+	}
+
+	r.seq++
+	event := ReplayEvent{
+		Seq:     r.seq,
+		Offset:  now.Sub(r.start),
+		Kind:    kind,
+		TaskID:  id,
+		Name:    info.Name,
+		Attempt: info.Attempt,
+		State:   info.State,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if kind == ReplayFinish || kind == ReplayCancel {
+		event.Duration = info.Duration()
+		delete(r.ids, tsk)
+	}
+	r.err = r.encoder.Encode(event)
+}
+
+// ReadReplayLog parse a replay log written by Recorder.
+func ReadReplayLog(r io.Reader) ([]ReplayEvent, error) {
+	var events []ReplayEvent
+	decoder := json.NewDecoder(r)
+	for {
+		var event ReplayEvent
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, fmt.Errorf("replay log event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+}
+
+// ReplayOptions defines options for Replay function
+type ReplayOptions struct {
+	// Sleep waits between events as recorded, default to time.Sleep.
+	// pass a fake clock's sleep (or a no-op) to drive the schedule without real waits.
+	Sleep func(time.Duration)
+}
+
+// Replay re-drives the schedule recorded in the log: tasks start, retry, finish and get canceled in the order,
+// and with gaps between events, recorded, each with its recorded outcome.
+// functions are looked up by task name, each recorded start starts a new task WithName, which runs the function,
+// and is held until its recorded retry or finish, to fail with the recorded error, or complete with result of the function.
+// events of tasks whose start isn't in the log are ignored. Replay returns once the whole log is replayed,
+// with handles in start order.
+func Replay(ctx context.Context, events []ReplayEvent, fns map[string]AsyncFunc, options *ReplayOptions) ([]*TaskStatus, error) {
+	sleep := time.Sleep
+	if options != nil && options.Sleep != nil {
+		sleep = options.Sleep
+	}
+
+	for _, event := range events {
+		if _, ok := fns[event.Name]; event.Kind == ReplayStart && !ok {
+			return nil, fmt.Errorf("replay: no function for task %q", event.Name)
+		}
+	}
+	events = append([]ReplayEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+
+	var tasks []*TaskStatus
+	replayed := map[int]*replayedTask{}
+	var last time.Duration
+	for _, event := range events {
+		replaying, ok := replayed[event.TaskID]
+		if event.Kind != ReplayStart && !ok {
+			continue
+		}
+
+		if gap := event.Offset - last; gap > 0 {
+			sleep(gap)
+		}
+		last = event.Offset
+		if err := ctx.Err(); err != nil {
+			return tasks, err
+		}
+
+		// each event is acknowledged by the task before the next one, so they happen in order.
+		switch event.Kind {
+		case ReplayStart:
+			replaying = startReplayedTask(ctx, fns[event.Name], event, events)
+			replayed[event.TaskID] = replaying
+			tasks = append(tasks, replaying.task)
+			replaying.waitAttempt(ctx)
+		case ReplayRetry:
+			replaying.outcomes <- recordedError(event)
+			replaying.waitAttempt(ctx)
+		case ReplayFinish:
+			replaying.outcomes <- recordedError(event)
+			_, _ = replaying.task.waitFinished(ctx)
+		case ReplayCancel:
+			replaying.task.Cancel()
+			_, _ = replaying.task.waitFinished(ctx)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return tasks, err
+	}
+	return tasks, nil
+}
+
+// replayedTask is a task started by Replay, held by the replay at each attempt.
+type replayedTask struct {
+	task *TaskStatus
+	// attempts receives when an attempt started.
+	attempts chan struct{}
+	// outcomes receives recorded error of each attempt, nil if it succeeded.
+	outcomes chan error
+}
+
+// startReplayedTask starts fn as the task of start, retried as many times as its retries recorded in events.
+func startReplayedTask(ctx context.Context, fn AsyncFunc, start ReplayEvent, events []ReplayEvent) *replayedTask {
+	r := &replayedTask{attempts: make(chan struct{}), outcomes: make(chan error, 1)}
+	retries := 0
+	for _, event := range events {
+		if event.Kind == ReplayRetry && event.TaskID == start.TaskID {
+			retries++
+		}
+	}
+	r.task = Start(ctx, func(ctx context.Context) (interface{}, error) {
+		select {
+		case r.attempts <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		result, _ := fn(ctx)
+		select {
+		case err := <-r.outcomes:
+			return result, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, WithName(start.Name), WithRetry(RetryPolicy{MaxAttempts: retries + 1, ShouldRetry: func(error) bool { return true }}))
+	return r
+}
+
+// waitAttempt block until the next attempt of the task started, or ctx is done.
+func (r *replayedTask) waitAttempt(ctx context.Context) {
+	select {
+	case <-r.attempts:
+	case <-ctx.Done():
+	}
+}
+
+// recordedError returns error recorded in event, nil if there is none.
+func recordedError(event ReplayEvent) error {
+	if event.Error == "" {
+		return nil
+	}
+	return errors.New(event.Error)
+}
@@ -0,0 +1,109 @@
+package asynctask_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// not parallel, it changes global sink.
+func TestRecorderAndReplay(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	log := &bytes.Buffer{}
+	recorder := asynctask.NewRecorder(log)
+	asynctask.SetEventSink(recorder)
+	fetch := asynctask.Start(ctx, getCountingTask(3, time.Millisecond), asynctask.WithName("fetch"))
+	_, err := fetch.Wait(ctx)
+	assert.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	parse := asynctask.Start(ctx, getErrorTask("dummy error", time.Millisecond), asynctask.WithName("parse"))
+	_, _ = parse.Wait(ctx)
+	asynctask.SetEventSink(nil)
+	assert.NoError(t, recorder.Err())
+
+	events, err := asynctask.ReadReplayLog(log)
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+	kinds := make([]asynctask.ReplayEventKind, 0, len(events))
+	for _, event := range events {
+		kinds = append(kinds, event.Kind)
+	}
+	assert.Equal(t, []asynctask.ReplayEventKind{asynctask.ReplayStart, asynctask.ReplayFinish, asynctask.ReplayStart, asynctask.ReplayFinish}, kinds)
+	assert.Equal(t, "fetch", events[0].Name)
+	assert.Equal(t, 1, events[0].TaskID)
+	assert.Equal(t, asynctask.StateCompleted, events[1].State)
+	assert.Equal(t, "parse", events[2].Name)
+	assert.Equal(t, 2, events[2].TaskID)
+	assert.Equal(t, "dummy error", events[3].Error)
+	assert.True(t, events[2].Offset >= 20*time.Millisecond)
+
+	// replay with a fake clock.
+	var slept time.Duration
+	tasks, err := asynctask.Replay(ctx, events, map[string]asynctask.AsyncFunc{
+		"fetch": getCountingTask(3, time.Millisecond),
+		"parse": getErrorTask("dummy error", time.Millisecond),
+	}, &asynctask.ReplayOptions{Sleep: func(d time.Duration) { slept += d }})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, events[3].Offset, slept)
+	assert.Equal(t, "fetch", tasks[0].Info().Name)
+	assert.Equal(t, "parse", tasks[1].Info().Name)
+	_, err = tasks[1].Wait(ctx)
+	assert.Equal(t, "dummy error", err.Error())
+
+	_, err = asynctask.Replay(ctx, events, map[string]asynctask.AsyncFunc{}, nil)
+	assert.Error(t, err)
+
+	_, err = asynctask.ReadReplayLog(strings.NewReader("{not json"))
+	assert.Error(t, err)
+}
+
+func TestReplayOutcomes(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// recorded: fetch outlived parse, which succeeded on retry, and crawl got canceled.
+	events := []asynctask.ReplayEvent{
+		{Seq: 1, Kind: asynctask.ReplayStart, TaskID: 1, Name: "fetch"},
+		{Seq: 2, Offset: time.Millisecond, Kind: asynctask.ReplayStart, TaskID: 2, Name: "parse"},
+		{Seq: 3, Offset: 2 * time.Millisecond, Kind: asynctask.ReplayStart, TaskID: 3, Name: "crawl"},
+		{Seq: 4, Offset: 3 * time.Millisecond, Kind: asynctask.ReplayRetry, TaskID: 2, Name: "parse", Error: "flaky"},
+		{Seq: 5, Offset: 4 * time.Millisecond, Kind: asynctask.ReplayCancel, TaskID: 3, Name: "crawl"},
+		{Seq: 6, Offset: 5 * time.Millisecond, Kind: asynctask.ReplayFinish, TaskID: 2, Name: "parse"},
+		{Seq: 7, Offset: 6 * time.Millisecond, Kind: asynctask.ReplayFinish, TaskID: 1, Name: "fetch", Error: "timeout"},
+	}
+
+	fn := func(name string) asynctask.AsyncFunc {
+		return func(context.Context) (interface{}, error) {
+			return name, nil
+		}
+	}
+	tasks, err := asynctask.Replay(ctx, events, map[string]asynctask.AsyncFunc{
+		"fetch": fn("fetch"),
+		"parse": fn("parse"),
+		"crawl": fn("crawl"),
+	}, &asynctask.ReplayOptions{Sleep: func(time.Duration) {}})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 3)
+	for _, tsk := range tasks {
+		assert.True(t, tsk.State().IsTerminalState(), "replay returns once log is replayed")
+	}
+
+	fetch, parse, crawl := tasks[0], tasks[1], tasks[2]
+	_, err = fetch.Wait(ctx)
+	assert.Equal(t, "timeout", err.Error())
+	result, err := parse.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "parse", result)
+	assert.Equal(t, 2, parse.Info().Attempt)
+	assert.Equal(t, asynctask.StateCanceled, crawl.State())
+	assert.True(t, parse.Info().FinishedAt.Before(fetch.Info().FinishedAt), "finish order is replayed")
+}
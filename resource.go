@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+	"sync"
+)
+
+// WithResource returns a task function acquiring a resource, running fn with it, and releasing it once the task terminates.
+// release runs exactly once, on whichever comes first: fn returns (or panics), or the task finishes otherwise (e.g. Cancel),
+// so a lock or lease isn't held by a canceled task still winding down. failed acquire fails the task without running fn.
+func WithResource[R, T any](acquire func(context.Context) (R, func(), error), fn func(context.Context, R) (T, error)) AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		resource, release, err := acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		once := sync.Once{}
+		releaseOnce := func() {
+			if release != nil {
+				once.Do(release)
+			}
+		}
+		defer releaseOnce()
+		if tsk := taskFromContext(ctx); tsk != nil {
+			tsk.onDone(releaseOnce)
+		}
+
+		return fn(ctx, resource)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithResource(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var held int32
+	acquire := func(ctx context.Context) (string, func(), error) {
+		atomic.AddInt32(&held, 1)
+		return "lock", func() { atomic.AddInt32(&held, -1) }, nil
+	}
+
+	result, err := asynctask.Start(ctx, asynctask.WithResource(acquire, func(ctx context.Context, lock string) (string, error) {
+		assert.Equal(t, int32(1), atomic.LoadInt32(&held))
+		return lock + " used", nil
+	})).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "lock used", result)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&held))
+
+	// panic
+	_, err = asynctask.Start(ctx, asynctask.WithResource(acquire, func(ctx context.Context, lock string) (string, error) {
+		panic("yo")
+	})).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&held))
+
+	// released on cancel, while function still winds down.
+	finish := make(chan struct{})
+	tsk := asynctask.Start(ctx, asynctask.WithResource(acquire, func(ctx context.Context, lock string) (string, error) {
+		<-finish
+		return "", nil
+	}))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&held) == 1 }, time.Second, time.Millisecond)
+	tsk.Cancel()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&held))
+	close(finish)
+
+	// failed acquire
+	_, err = asynctask.Start(ctx, asynctask.WithResource(func(ctx context.Context) (string, func(), error) {
+		return "", nil, errors.New("lease taken")
+	}, func(ctx context.Context, lock string) (string, error) {
+		assert.Fail(t, "should not run")
+		return "", nil
+	})).Wait(ctx)
+	assert.Equal(t, "lease taken", err.Error())
+}
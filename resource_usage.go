@@ -0,0 +1,57 @@
+package asynctask
+
+import "runtime"
+
+// ResourceUsage is approximate resource usage of a task, sampled around its function, see WithResourceSampling.
+// samples are process wide: anything else running at the same time is counted as well,
+// compare many runs of a task type rather than trusting a single one.
+type ResourceUsage struct {
+	// Sampled is false if task wasn't started WithResourceSampling, or its function didn't return yet.
+	Sampled bool
+	// GoroutineDelta is the change in number of goroutines, from before the function ran to after it returned,
+	// a task type with a positive delta run after run likely leaks goroutines.
+	GoroutineDelta int
+	// AllocBytes and Allocs are heap allocations made while the function ran.
+	AllocBytes uint64
+	Allocs     uint64
+}
+
+// WithResourceSampling records goroutine and heap allocation deltas around the task function, see ResourceUsage.
+// reading memory stats stops the world briefly, so it's opt-in, for finding which task types leak.
+func WithResourceSampling() TaskOption {
+	return func(o *taskOptions) {
+		o.sampleUsage = true
+	}
+}
+
+// ResourceUsage returns resource usage of the task, once its function returned.
+func (t *TaskStatus) ResourceUsage() ResourceUsage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.usage
+}
+
+// sampleUsage take a sample before the task function runs, stop should be called once it returned to record usage.
+func (t *TaskStatus) sampleUsage() (stop func()) {
+	if !t.options.sampleUsage {
+		return func() {}
+	}
+
+	goroutines := runtime.NumGoroutine()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	return func() {
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		usage := ResourceUsage{
+			Sampled:        true,
+			GoroutineDelta: runtime.NumGoroutine() - goroutines,
+			AllocBytes:     after.TotalAlloc - before.TotalAlloc,
+			Allocs:         after.Mallocs - before.Mallocs,
+		}
+
+		t.mutex.Lock()
+		t.usage = usage
+		t.mutex.Unlock()
+	}
+}
@@ -0,0 +1,40 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+var allocSink []byte
+
+// not parallel, goroutines of other tests would skew the delta.
+func TestWithResourceSampling(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	leaked := make(chan struct{})
+	defer close(leaked)
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		allocSink = make([]byte, 1<<20)
+		go func() { <-leaked }()
+		return nil, nil
+	}, asynctask.WithResourceSampling())
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+
+	usage := tsk.ResourceUsage()
+	assert.True(t, usage.Sampled)
+	assert.GreaterOrEqual(t, usage.GoroutineDelta, 1)
+	assert.GreaterOrEqual(t, usage.AllocBytes, uint64(1<<20))
+	assert.GreaterOrEqual(t, usage.Allocs, uint64(1))
+
+	// not opted in.
+	tsk = asynctask.Start(ctx, getCountingTask(1, time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.ResourceUsage{}, tsk.ResourceUsage())
+}
@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+// Result is a value or the error which prevented it, e.g. one element of ParallelMap output.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Ok tells whether there is a value, i.e. no error.
+func (r Result[T]) Ok() bool {
+	return r.Err == nil
+}
+
+// Unwrap returns the value and error as a pair, for the usual if err != nil check.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.Value, r.Err
+}
+
+// ValueOr returns the value, or fallback if there is an error.
+func (r Result[T]) ValueOr(fallback T) T {
+	if r.Err != nil {
+		return fallback
+	}
+	return r.Value
+}
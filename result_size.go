@@ -0,0 +1,137 @@
+package asynctask
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrResultTooLarge is returned if result of a task started WithMaxResultSize exceeds the limit.
+var ErrResultTooLarge = errors.New("result too large")
+
+// ResultSizer returns size of a result in bytes.
+type ResultSizer func(result interface{}) int64
+
+// Sizer can be implemented by a result to report its own size in bytes.
+type Sizer interface {
+	Size() int64
+}
+
+// WithMaxResultSize fails the task with ErrResultTooLarge if its result is larger than maxBytes,
+// so the result is dropped instead of held by the handle.
+// sizer measures the result, nil uses a heuristic walking the result: Sizer if implemented, length of strings,
+// content of slices, arrays and maps, fields of structs, and what pointers and interfaces point to, size of the value otherwise.
+// shared content is counted once, and the walk stops 16 levels deep.
+func WithMaxResultSize(maxBytes int64, sizer ResultSizer) TaskOption {
+	return func(o *taskOptions) {
+		if sizer == nil {
+			sizer = estimateSize
+		}
+		o.maxResultSize = maxBytes
+		o.resultSizer = sizer
+	}
+}
+
+// checkResultSize returns ErrResultTooLarge if result exceeds size limit of the task.
+func (t *TaskStatus) checkResultSize(result interface{}) error {
+	if t.options.resultSizer == nil {
+		return nil
+	}
+	if size := t.options.resultSizer(result); size > t.options.maxResultSize {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrResultTooLarge, size, t.options.maxResultSize)
+	}
+	return nil
+}
+
+// maxSizeDepth is how deep estimateSize walks into a result, deeper values count as their own size.
+const maxSizeDepth = 16
+
+// estimateSize is the default ResultSizer.
+func estimateSize(result interface{}) int64 {
+	if result == nil {
+		return 0
+	}
+	return sizeOf(reflect.ValueOf(result), map[uintptr]bool{}, 0)
+}
+
+// sizeOf returns size of v and what it refers to, content already in visited is not counted again.
+func sizeOf(v reflect.Value, visited map[uintptr]bool, depth int) int64 {
+	nilRef := (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()
+	if v.CanInterface() && !nilRef {
+		if sizer, ok := v.Interface().(Sizer); ok {
+			return sizer.Size()
+		}
+	}
+	if depth > maxSizeDepth {
+		return int64(v.Type().Size())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if nilRef || v.Kind() == reflect.Ptr && !visit(visited, v.Pointer()) {
+			return 0
+		}
+		return sizeOf(v.Elem(), visited, depth+1)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || !visit(visited, v.Pointer())) {
+			return 0
+		}
+		if isFlat(v.Type().Elem()) {
+			return int64(v.Len()) * int64(v.Type().Elem().Size())
+		}
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += sizeOf(v.Index(i), visited, depth+1)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || !visit(visited, v.Pointer()) {
+			return 0
+		}
+		var size int64
+		for iter := v.MapRange(); iter.Next(); {
+			size += sizeOf(iter.Key(), visited, depth+1) + sizeOf(iter.Value(), visited, depth+1)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += sizeOf(v.Field(i), visited, depth+1)
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
+
+// visit marks content at pointer visited, returns false if it already was.
+func visit(visited map[uintptr]bool, pointer uintptr) bool {
+	if visited[pointer] {
+		return false
+	}
+	visited[pointer] = true
+	return true
+}
+
+// isFlat tells whether values of t refer to nothing, so their size is the size of t.
+func isFlat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isFlat(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isFlat(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type sizedResult struct{}
+
+func (sizedResult) Size() int64 { return 1 << 30 }
+
+func getResultTask(result interface{}) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		return result, nil
+	}
+}
+
+func TestMaxResultSize(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	result, err := asynctask.Start(ctx, getResultTask(make([]byte, 100)), asynctask.WithMaxResultSize(100, nil)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, result, 100)
+
+	for _, tooLarge := range []interface{}{make([]byte, 101), make([]int64, 20), string(make([]byte, 101)), map[int64]int64{1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7}, sizedResult{}} {
+		tsk := asynctask.Start(ctx, getResultTask(tooLarge), asynctask.WithMaxResultSize(100, nil))
+		result, err = tsk.Wait(ctx)
+		assert.True(t, errors.Is(err, asynctask.ErrResultTooLarge), "expecting ErrResultTooLarge for %T", tooLarge)
+		assert.Nil(t, result)
+		assert.Equal(t, asynctask.StateFailed, tsk.State())
+	}
+
+	// payload wrapped in structs, pointers and maps is counted.
+	type payload struct {
+		Data []byte
+	}
+	type envelope struct {
+		Body  *payload
+		Parts map[string]payload
+	}
+	for _, wrapped := range []interface{}{
+		payload{Data: make([]byte, 101)},
+		&payload{Data: make([]byte, 101)},
+		envelope{Parts: map[string]payload{"body": {Data: make([]byte, 101)}}},
+		[]interface{}{&envelope{Body: &payload{Data: make([]byte, 101)}}},
+	} {
+		_, err = asynctask.Start(ctx, getResultTask(wrapped), asynctask.WithMaxResultSize(100, nil)).Wait(ctx)
+		assert.True(t, errors.Is(err, asynctask.ErrResultTooLarge), "expecting ErrResultTooLarge for %T", wrapped)
+	}
+
+	// shared content is counted once, cycles end, and nil is empty.
+	shared := &payload{Data: make([]byte, 60)}
+	type node struct {
+		Next *node
+	}
+	cycle := &node{}
+	cycle.Next = cycle
+	var nilSizer *sizedResult
+	for _, fits := range []interface{}{[]*payload{shared, shared}, cycle, nilSizer} {
+		_, err = asynctask.Start(ctx, getResultTask(fits), asynctask.WithMaxResultSize(100, nil)).Wait(ctx)
+		assert.NoError(t, err)
+	}
+
+	// custom sizer
+	_, err = asynctask.Start(ctx, getResultTask("small"), asynctask.WithMaxResultSize(100, func(result interface{}) int64 {
+		return 1000
+	})).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrResultTooLarge), "expecting ErrResultTooLarge")
+}
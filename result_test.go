@@ -0,0 +1,35 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+
+	ok := asynctask.Result[int]{Value: 42}
+	assert.True(t, ok.Ok())
+	assert.Equal(t, 42, ok.ValueOr(-1))
+	value, err := ok.Unwrap()
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	errBad := errors.New("bad item")
+	failed := asynctask.Result[int]{Err: errBad}
+	assert.False(t, failed.Ok())
+	assert.Equal(t, -1, failed.ValueOr(-1))
+	_, err = failed.Unwrap()
+	assert.Equal(t, errBad, err)
+
+	// MapResult carries a Result.
+	mapped := asynctask.MapResult[int]{Index: 3, Result: failed}
+	assert.Equal(t, -1, mapped.ValueOr(-1))
+	assert.Equal(t, errBad, mapped.Err)
+}
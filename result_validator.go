@@ -0,0 +1,21 @@
+package asynctask
+
+// WithResultValidator checks result of the task once the function returned without error,
+// error from validator fails the task instead, so invalid results never reach waiters.
+// validator only runs on success, after all retries, see WithRetry.
+func WithResultValidator(validator func(result interface{}) error) TaskOption {
+	return func(o *taskOptions) {
+		o.validator = validator
+	}
+}
+
+// validate checks size limit of the task (see WithMaxResultSize), then runs validator of the task on result.
+func (t *TaskStatus) validate(result interface{}) error {
+	if err := t.checkResultSize(result); err != nil {
+		return err
+	}
+	if t.options.validator == nil {
+		return nil
+	}
+	return t.options.validator(result)
+}
@@ -0,0 +1,56 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithResultValidator(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errTooSmall := errors.New("result too small")
+	atLeast := func(min int) func(interface{}) error {
+		return func(result interface{}) error {
+			if result.(int) < min {
+				return errTooSmall
+			}
+			return nil
+		}
+	}
+
+	tsk := asynctask.Start(ctx, getCountingTask(3, time.Millisecond), asynctask.WithResultValidator(atLeast(2)))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+
+	tsk = asynctask.Start(ctx, getCountingTask(3, time.Millisecond), asynctask.WithResultValidator(atLeast(5)))
+	result, err = tsk.Wait(ctx)
+	assert.Equal(t, errTooSmall, err)
+	assert.Nil(t, result)
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// validator doesn't run on failure.
+	tsk = asynctask.Start(ctx, getErrorTask("dummy error", time.Millisecond), asynctask.WithResultValidator(func(interface{}) error {
+		panic("shouldn't validate")
+	}))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, "dummy error", err.Error())
+
+	// panic in validator fails the task like panic in the function.
+	tsk = asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		return nil, nil
+	}, asynctask.WithResultValidator(func(result interface{}) error {
+		_ = result.(int)
+		return nil
+	}))
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+}
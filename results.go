@@ -0,0 +1,60 @@
+//go:build go1.23
+// +build go1.23
+
+package asynctask
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// Results returns an iterator over outcome of the tasks, in the order they finish:
+//
+//	for result, err := range asynctask.Results(ctx, tasks...) { ... }
+//
+// if ctx is done before all tasks finished, it yields (nil, ctx.Err()) and stops.
+// breaking out of the loop is fine, remaining tasks keep running.
+func Results(ctx context.Context, tasks ...*TaskStatus) iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		finished := make(chan *TaskStatus, len(tasks))
+		for _, tsk := range tasks {
+			tsk := tsk
+			tsk.onDone(func() {
+				finished <- tsk
+			})
+		}
+
+		for range tasks {
+			select {
+			case tsk := <-finished:
+				if !yield(tsk.outcome()) {
+					return
+				}
+			case <-ctx.Done():
+				yield(nil, ctx.Err())
+				return
+			}
+		}
+	}
+}
+
+// ResultsOf is Results with result converted to T,
+// a result of other type is yield as zero value of T with an error.
+func ResultsOf[T any](ctx context.Context, tasks ...*TaskStatus) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for rawResult, err := range Results(ctx, tasks...) {
+			var result T
+			if rawResult != nil {
+				typed, ok := rawResult.(T)
+				if !ok && err == nil {
+					err = fmt.Errorf("unexpected result type %T, expecting %T", rawResult, result)
+				}
+				result = typed
+			}
+			if !yield(result, err) {
+				return
+			}
+		}
+	}
+}
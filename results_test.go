@@ -0,0 +1,66 @@
+//go:build go1.23
+// +build go1.23
+
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResults(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	slow := asynctask.Start(ctx, getAdvancedCountingTask(100, 3, 20*time.Millisecond))
+	failed := asynctask.Start(ctx, getErrorTask("expected error", 10*time.Millisecond))
+	fast := asynctask.Start(ctx, getAdvancedCountingTask(0, 1, time.Millisecond))
+
+	var results []interface{}
+	var errs []error
+	for result, err := range asynctask.Results(ctx, slow, failed, fast) {
+		results = append(results, result)
+		errs = append(errs, err)
+	}
+
+	assert.Equal(t, []interface{}{1, nil, 103}, results)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, "expected error", errs[1].Error())
+	assert.NoError(t, errs[2])
+}
+
+func TestResultsEarlyStop(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	slow := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	defer slow.Cancel()
+	fast := asynctask.Start(ctx, getAdvancedCountingTask(0, 1, time.Millisecond))
+
+	for result, err := range asynctask.ResultsOf[int](ctx, slow, fast) {
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+		break
+	}
+
+	// ctx done before slow task finish
+	waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelWait()
+	var errs []error
+	for _, err := range asynctask.Results(waitCtx, slow) {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+
+	// wrong type
+	for result, err := range asynctask.ResultsOf[string](ctx, fast) {
+		assert.Error(t, err)
+		assert.Equal(t, "", result)
+	}
+}
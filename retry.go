@@ -0,0 +1,102 @@
+package asynctask
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy defines how a failed task function is retried.
+type RetryPolicy struct {
+	// MaxAttempts is total number of attempts, including the first one.
+	MaxAttempts int
+	// Backoff is the wait before first retry.
+	Backoff time.Duration
+	// Multiplier grows the wait after each retry, <= 1 means fixed wait.
+	Multiplier float64
+	// MaxBackoff caps the wait, zero means no cap.
+	MaxBackoff time.Duration
+	// ShouldRetry tells whether the error is worth retrying,
+	// default to retry retryable classes if task has WithErrorClassifier, any error otherwise.
+	ShouldRetry func(error) bool
+}
+
+// WithRetry runs the task function again when it returns error, as the policy allows.
+// task is in StateRetrying while waiting for next attempt, and panic is never retried.
+func WithRetry(policy RetryPolicy) TaskOption {
+	return func(o *taskOptions) {
+		o.retry = &policy
+	}
+}
+
+// isStaged tells whether task function used RunStage, which retries on its own.
+func (t *TaskStatus) isStaged() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.staged
+}
+
+// runWithRetry runs the task function, and retries it according to retry policy of the task.
+func (t *TaskStatus) runWithRetry(ctx context.Context, task AsyncFunc) (interface{}, error) {
+	return t.retry(ctx, t.options.retry, func(err error) bool {
+		return !t.isStaged() && t.shouldRetry(err)
+	}, func(ctx context.Context, attempt int) (interface{}, error) {
+		ctx, stopAttempt := t.startAttemptTimeout(ctx)
+		defer stopAttempt()
+		attemptStart := time.Now()
+		result, err := task(ctx)
+		t.traceAttempt(attempt, attemptStart, err)
+		return result, err
+	})
+}
+
+// retry runs attempt, and retries it as policy allows and shouldRetry agrees, nil policy runs it once.
+// it's the one retry loop of the task, its function and its stages (see RunStage) and workflow steps alike:
+// task records the attempt, is in StateRetrying while waiting for next attempt, and OnRetry is emitted.
+// error of the last attempt is returned, ctx.Err() if ctx is done while waiting.
+func (t *TaskStatus) retry(ctx context.Context, policy *RetryPolicy, shouldRetry func(error) bool, attempt func(ctx context.Context, attempt int) (interface{}, error)) (interface{}, error) {
+	var backoff time.Duration
+	if policy != nil {
+		backoff = policy.Backoff
+	}
+
+	for n := 1; ; n++ {
+		t.mutex.Lock()
+		t.attempt = n
+		t.mutex.Unlock()
+
+		result, err := attempt(ctx, n)
+		if err == nil || !isErrorReallyError(err) ||
+			policy == nil || n >= policy.MaxAttempts || !shouldRetry(err) {
+			return result, err
+		}
+
+		if t.transition(StateRetrying) != nil {
+			// task is no longer running, e.g. canceled.
+			return result, err
+		}
+		t.emitRetry(n, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if t.transition(StateRunning) != nil {
+			return result, err
+		}
+		backoff = nextInterval(backoff, policy.Multiplier, policy.MaxBackoff)
+	}
+}
+
+// shouldRetry tells whether err is worth retrying, per retry policy and error classifier of the task.
+func (t *TaskStatus) shouldRetry(err error) bool {
+	switch {
+	case t.options.retry.ShouldRetry != nil:
+		return t.options.retry.ShouldRetry(err)
+	case t.options.classifier != nil:
+		return t.classify(err).IsRetryable()
+	default:
+		return true
+	}
+}
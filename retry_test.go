@@ -0,0 +1,85 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// getFlakyTask fails until it has been called succeedOn times.
+func getFlakyTask(succeedOn int32, calls *int32) asynctask.AsyncFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		call := atomic.AddInt32(calls, 1)
+		if call < succeedOn {
+			return nil, errors.New("transient error")
+		}
+		return call, nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var calls int32
+	tsk := asynctask.Start(ctx, getFlakyTask(3, &calls), asynctask.WithRetry(asynctask.RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     time.Millisecond,
+		Multiplier:  2,
+	}))
+	changes := tsk.StateChanges()
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), result)
+	assert.Equal(t, 3, tsk.Info().Attempt)
+	assert.Equal(t, []asynctask.State{
+		asynctask.StateRunning,
+		asynctask.StateRetrying, asynctask.StateRunning,
+		asynctask.StateRetrying, asynctask.StateRunning,
+		asynctask.StateCompleted,
+	}, collectStates(changes, time.Second))
+}
+
+func TestRetryExhausted(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var calls int32
+	tsk := asynctask.Start(ctx, getFlakyTask(10, &calls), asynctask.WithRetry(asynctask.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}))
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, "transient error", err.Error())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// error not worth retrying fails right away.
+	calls = 0
+	tsk = asynctask.Start(ctx, getFlakyTask(10, &calls), asynctask.WithRetry(asynctask.RetryPolicy{
+		MaxAttempts: 3,
+		ShouldRetry: func(err error) bool { return false },
+	}))
+	_, err = tsk.Wait(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// cancel while waiting for next attempt.
+	calls = 0
+	tsk = asynctask.Start(ctx, getFlakyTask(10, &calls), asynctask.WithRetry(asynctask.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+	}))
+	assert.Eventually(t, func() bool { return tsk.State() == asynctask.StateRetrying }, time.Second, time.Millisecond)
+	tsk.Cancel()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+}
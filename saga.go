@@ -0,0 +1,97 @@
+package asynctask
+
+import (
+	"context"
+	"sync"
+)
+
+// CompensateFunc undoes a completed saga step, result is what the step returned.
+type CompensateFunc func(ctx context.Context, result interface{}) error
+
+// Saga tracks completed steps of a multi-step task, so they can be undone if a later step fails, see StartSaga.
+type Saga struct {
+	mutex sync.Mutex
+	steps []sagaStep
+}
+
+type sagaStep struct {
+	name       string
+	result     interface{}
+	compensate CompensateFunc
+}
+
+// SagaError is the error of a failed saga, carrying errors of compensations which failed as well.
+type SagaError struct {
+	// Err is the error the saga failed with.
+	Err error
+	// CompensationErrors are errors of failed compensations, in order they ran.
+	CompensationErrors []error
+}
+
+func (e *SagaError) Error() string {
+	if len(e.CompensationErrors) == 0 {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ", compensation failed: " + aggregate(e.CompensationErrors).Error()
+}
+
+// Unwrap returns the error the saga failed with.
+func (e *SagaError) Unwrap() error {
+	return e.Err
+}
+
+// StartSaga run fn as a task, fn runs its steps through Saga.Step.
+// if fn fails, compensations of completed steps run in reverse order, each as its own task named "compensate <step>",
+// detached from ctx so cleanup still happens after cancel. task fails with a SagaError.
+// fn or a step panicking fails the saga as well, with a SagaError wrapping the PanicError.
+func StartSaga(ctx context.Context, fn func(ctx context.Context, saga *Saga) (interface{}, error), opts ...TaskOption) *TaskStatus {
+	return Start(ctx, func(fCtx context.Context) (result interface{}, err error) {
+		saga := &Saga{}
+		defer func() {
+			if r := recover(); r != nil {
+				result, err = nil, &SagaError{Err: newPanicError(r), CompensationErrors: saga.compensate(fCtx)}
+			}
+		}()
+		result, err = fn(fCtx, saga)
+		if err == nil {
+			return result, nil
+		}
+		return nil, &SagaError{Err: err, CompensationErrors: saga.compensate(fCtx)}
+	}, opts...)
+}
+
+// Step runs action of a step, and registers compensate to undo it once it succeeded.
+func (s *Saga) Step(ctx context.Context, name string, action AsyncFunc, compensate CompensateFunc) (interface{}, error) {
+	result, err := action(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.steps = append(s.steps, sagaStep{name: name, result: result, compensate: compensate})
+	return result, nil
+}
+
+// compensate runs compensations of completed steps in reverse order, and returns errors of failed ones.
+func (s *Saga) compensate(ctx context.Context) []error {
+	s.mutex.Lock()
+	steps := s.steps
+	s.steps = nil
+	s.mutex.Unlock()
+
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.compensate == nil {
+			continue
+		}
+		tsk := Start(ctx, func(fCtx context.Context) (interface{}, error) {
+			return nil, step.compensate(fCtx, step.result)
+		}, WithName("compensate "+step.name), WithDetachedContext())
+		if _, err := tsk.waitFinished(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,103 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaga(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	mutex := sync.Mutex{}
+	var undone []interface{}
+	getCompensation := func(err error) asynctask.CompensateFunc {
+		return func(ctx context.Context, result interface{}) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			undone = append(undone, result)
+			return err
+		}
+	}
+
+	tsk := asynctask.StartSaga(ctx, func(ctx context.Context, saga *asynctask.Saga) (interface{}, error) {
+		if _, err := saga.Step(ctx, "createVM", getResultTask("vm-1"), getCompensation(nil)); err != nil {
+			return nil, err
+		}
+		if _, err := saga.Step(ctx, "attachDisk", getResultTask("disk-1"), getCompensation(errors.New("disk busy"))); err != nil {
+			return nil, err
+		}
+		if _, err := saga.Step(ctx, "configureDNS", getErrorTask("dns unavailable", time.Millisecond), getCompensation(nil)); err != nil {
+			return nil, err
+		}
+		return "provisioned", nil
+	}, asynctask.WithName("provision"))
+
+	_, err := tsk.Wait(ctx)
+	var sagaErr *asynctask.SagaError
+	assert.True(t, errors.As(err, &sagaErr))
+	assert.Equal(t, "dns unavailable", sagaErr.Err.Error())
+	assert.Equal(t, []error{errors.New("disk busy")}, sagaErr.CompensationErrors)
+	assert.Equal(t, "dns unavailable, compensation failed: disk busy", err.Error())
+	// completed steps undone in reverse order, the failed one is not.
+	assert.Equal(t, []interface{}{"disk-1", "vm-1"}, undone)
+
+	// nothing to undo on success.
+	undone = nil
+	result, err := asynctask.StartSaga(ctx, func(ctx context.Context, saga *asynctask.Saga) (interface{}, error) {
+		return saga.Step(ctx, "createVM", getResultTask("vm-1"), getCompensation(nil))
+	}).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "vm-1", result)
+	assert.Nil(t, undone)
+
+	// compensation still runs once saga is canceled.
+	canceledCtx, cancel := context.WithCancel(ctx)
+	tsk = asynctask.StartSaga(canceledCtx, func(ctx context.Context, saga *asynctask.Saga) (interface{}, error) {
+		if _, err := saga.Step(ctx, "createVM", getResultTask("vm-2"), getCompensation(nil)); err != nil {
+			return nil, err
+		}
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err = tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), "expecting context.Canceled")
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(undone) == 1 && undone[0] == "vm-2"
+	}, time.Second, time.Millisecond)
+}
+
+func TestSagaPanic(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var undone []interface{}
+	tsk := asynctask.StartSaga(ctx, func(ctx context.Context, saga *asynctask.Saga) (interface{}, error) {
+		if _, err := saga.Step(ctx, "createVM", getResultTask("vm-1"), func(ctx context.Context, result interface{}) error {
+			undone = append(undone, result)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return saga.Step(ctx, "attachDisk", func(ctx context.Context) (interface{}, error) {
+			panic("disk driver crashed")
+		}, nil)
+	})
+
+	_, err := tsk.Wait(ctx)
+	var sagaErr *asynctask.SagaError
+	assert.True(t, errors.As(err, &sagaErr))
+	assert.True(t, errors.Is(err, asynctask.ErrPanic), "expecting ErrPanic")
+	assert.Equal(t, []interface{}{"vm-1"}, undone)
+}
@@ -0,0 +1,32 @@
+package asynctask
+
+import (
+	"context"
+	"time"
+)
+
+// StartAfter run a async function after delay, and returns you a handle which you can Wait or Cancel right away.
+// task is in StateScheduled until it starts, canceled task (or context) before that never runs.
+// delays are kept in a process wide timer wheel of millisecond resolution, so scheduling lots of tasks is cheap.
+func StartAfter(ctx context.Context, delay time.Duration, task AsyncFunc, opts ...TaskOption) *TaskStatus {
+	options := newTaskOptions(opts)
+	ctx, release := options.taskContext(ctx)
+	taskCtx, cancel := context.WithCancel(ctx)
+	record := newRunningTask(cancel, options)
+	record.state = StateScheduled
+	record.trackDefault()
+	record.onDone(release)
+
+	stop := failOnContextDone(ctx, taskCtx, record)
+	timer := schedulerWheel.afterFunc(delay, func() {
+		if !stop() || record.State().IsTerminalState() {
+			return
+		}
+		runAndTrackTask(taskCtx, record, task)
+	})
+	record.onDone(func() {
+		timer.Stop()
+	})
+
+	return record
+}
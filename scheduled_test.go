@@ -0,0 +1,52 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartAfter(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	start := time.Now()
+	tsk := asynctask.StartAfter(ctx, 20*time.Millisecond, getCountingTask(2, time.Millisecond))
+	assert.Equal(t, asynctask.StateScheduled, tsk.State())
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	assert.True(t, tsk.Info().StartedAt.Sub(start) >= 20*time.Millisecond)
+	assert.True(t, tsk.Info().QueueDuration() >= 20*time.Millisecond)
+}
+
+func TestStartAfterCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	ran := make(chan struct{}, 2)
+	neverRun := func(ctx context.Context) (interface{}, error) {
+		ran <- struct{}{}
+		return nil, nil
+	}
+
+	tsk := asynctask.StartAfter(ctx, 20*time.Millisecond, neverRun)
+	tsk.Cancel()
+	assert.Equal(t, asynctask.StateCanceled, tsk.State())
+
+	scheduleCtx, cancelSchedule := context.WithCancel(ctx)
+	tsk2 := asynctask.StartAfter(scheduleCtx, 20*time.Millisecond, neverRun)
+	cancelSchedule()
+	_, err := tsk2.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, asynctask.StateFailed, tsk2.State())
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Len(t, ran, 0)
+}
@@ -0,0 +1,38 @@
+package asynctask
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// WithSignals returns a copy of ctx which is canceled when any of the signals arrives,
+// or when the returned stop function is called, whichever happens first.
+// stop also unregisters the signals, so another arrival behaves as default (e.g. terminates the process).
+//
+// Common graceful shutdown pattern with a Registry:
+//
+//	ctx, stop := asynctask.WithSignals(context.Background(), os.Interrupt, syscall.SIGTERM)
+//	defer stop()
+//	registry.Track(asynctask.Start(context.Background(), work))
+//	<-ctx.Done()
+//	err := registry.Drain(context.Background(), &asynctask.DrainOptions{DrainTimeout: 30 * time.Second})
+func WithSignals(ctx context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		select {
+		case <-ch:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(ch)
+	}()
+
+	return ctx, func() {
+		cancel()
+		signal.Stop(ch)
+	}
+}
@@ -0,0 +1,41 @@
+package asynctask_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signal to self is not supported on windows")
+	}
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	registry.Track(asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond)))
+
+	sigCtx, stop := asynctask.WithSignals(ctx, os.Interrupt)
+	defer stop()
+
+	self, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, self.Signal(os.Interrupt))
+
+	select {
+	case <-sigCtx.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "context should be canceled on signal")
+	}
+	assert.Equal(t, context.Canceled, sigCtx.Err())
+
+	err = registry.Drain(ctx, &asynctask.DrainOptions{DrainTimeout: 10 * time.Millisecond})
+	assert.Error(t, err)
+	assert.Equal(t, 0, registry.Len())
+}
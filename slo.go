@@ -0,0 +1,126 @@
+package asynctask
+
+import (
+	"sort"
+	"time"
+)
+
+// SLO is the objective of tasks of a name, evaluated over the most recent ones (up to 1024) finished while tracked in a Registry.
+// canceled and skipped tasks don't count.
+type SLO struct {
+	// SuccessRate is the fraction of tasks which should complete rather than fail, e.g. 0.99, zero for no objective.
+	SuccessRate float64
+	// LatencyRate is the fraction of completed tasks which should run within Latency, e.g. 0.95 within 2s, zero for no objective.
+	Latency     time.Duration
+	LatencyRate float64
+	// MinSamples is number of finished tasks before the objective is evaluated, so a single early failure doesn't breach it.
+	MinSamples int
+}
+
+// SLOStatus is how tasks of a name do against their objective.
+type SLOStatus struct {
+	Name      string
+	Objective SLO
+	// Samples is number of tasks the rates are over.
+	Samples     int
+	SuccessRate float64
+	// LatencyRate is over completed tasks only, 1 if none.
+	LatencyRate float64
+	Breached    bool
+}
+
+// sloSample is the outcome of a finished task.
+type sloSample struct {
+	completed bool
+	duration  time.Duration
+}
+
+// sloWindow keeps most recent outcomes of tasks of a name in a ring.
+type sloWindow struct {
+	objective SLO
+	samples   []sloSample
+	next      int
+	breached  bool
+}
+
+func (w *sloWindow) add(sample sloSample) {
+	if len(w.samples) < waitWindowSize {
+		w.samples = append(w.samples, sample)
+		return
+	}
+	w.samples[w.next] = sample
+	w.next = (w.next + 1) % waitWindowSize
+}
+
+func (w *sloWindow) status(name string) SLOStatus {
+	status := SLOStatus{Name: name, Objective: w.objective, Samples: len(w.samples), SuccessRate: 1, LatencyRate: 1}
+	completed, fast := 0, 0
+	for _, sample := range w.samples {
+		if !sample.completed {
+			continue
+		}
+		completed++
+		if sample.duration <= w.objective.Latency {
+			fast++
+		}
+	}
+	if len(w.samples) > 0 {
+		status.SuccessRate = float64(completed) / float64(len(w.samples))
+	}
+	if completed > 0 {
+		status.LatencyRate = float64(fast) / float64(completed)
+	}
+
+	if status.Samples > 0 && status.Samples >= w.objective.MinSamples {
+		status.Breached = status.SuccessRate < w.objective.SuccessRate ||
+			(w.objective.Latency > 0 && status.LatencyRate < w.objective.LatencyRate)
+	}
+	return status
+}
+
+// SetSLO sets the objective of tasks named name, and restarts its evaluation.
+func (r *Registry) SetSLO(name string, objective SLO) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.slos == nil {
+		r.slos = map[string]*sloWindow{}
+	}
+	r.slos[name] = &sloWindow{objective: objective}
+}
+
+// SLOReport returns status of every name with an objective, sorted by name.
+func (r *Registry) SLOReport() []SLOStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	report := make([]SLOStatus, 0, len(r.slos))
+	for name, window := range r.slos {
+		report = append(report, window.status(name))
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report
+}
+
+// OnSLOBreach register a callback receiving status of a name each time it starts breaching its objective,
+// and again once it's back within (Breached is false then), e.g. for in-process alerting.
+// it runs on the routine which finished the task, like OnDone.
+func (r *Registry) OnSLOBreach(callback func(SLOStatus)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sloCallbacks = append(r.sloCallbacks, callback)
+}
+
+// recordSLO record outcome of a finished task against objective of its name,
+// returns callbacks to run with status if that changed whether the objective is breached. mutex should be held.
+func (r *Registry) recordSLO(info TaskInfo) (callbacks []func(SLOStatus), status SLOStatus) {
+	window, ok := r.slos[info.Name]
+	if !ok || (info.State != StateCompleted && info.State != StateFailed) {
+		return nil, status
+	}
+	window.add(sloSample{completed: info.State == StateCompleted, duration: info.Duration()})
+	status = window.status(info.Name)
+	if status.Breached == window.breached {
+		return nil, status
+	}
+	window.breached = status.Breached
+	return r.sloCallbacks, status
+}
@@ -0,0 +1,97 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrySLO(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	registry.SetSLO("sync", asynctask.SLO{SuccessRate: 0.75, Latency: 50 * time.Millisecond, LatencyRate: 0.5, MinSamples: 4})
+	mutex := sync.Mutex{}
+	var alerts []asynctask.SLOStatus
+	registry.OnSLOBreach(func(status asynctask.SLOStatus) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		alerts = append(alerts, status)
+	})
+
+	run := func(fn asynctask.AsyncFunc, name string) {
+		tsk := asynctask.Start(ctx, fn, asynctask.WithName(name))
+		registry.Track(tsk)
+		_, _ = tsk.Wait(ctx)
+		assert.Eventually(t, func() bool { return registry.Len() == 0 }, time.Second, time.Millisecond)
+	}
+	fail := func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("sync failed")
+	}
+	ok := getCountingTask(1, time.Millisecond)
+
+	// not evaluated before min samples.
+	run(fail, "sync")
+	run(fail, "sync")
+	run(ok, "sync")
+	report := registry.SLOReport()
+	assert.Len(t, report, 1)
+	assert.Equal(t, "sync", report[0].Name)
+	assert.Equal(t, 3, report[0].Samples)
+	assert.InDelta(t, 1.0/3, report[0].SuccessRate, 0.001)
+	assert.False(t, report[0].Breached)
+
+	run(ok, "sync")
+	// other names and canceled tasks don't count.
+	run(fail, "other")
+	canceled := asynctask.Start(ctx, getCountingTask(10, 10*time.Millisecond), asynctask.WithName("sync"))
+	registry.Track(canceled)
+	canceled.Cancel()
+
+	report = registry.SLOReport()
+	assert.Equal(t, 4, report[0].Samples)
+	assert.Equal(t, 0.5, report[0].SuccessRate)
+	assert.Equal(t, 1.0, report[0].LatencyRate)
+	assert.True(t, report[0].Breached)
+
+	for i := 0; i < 4; i++ {
+		run(ok, "sync")
+	}
+	report = registry.SLOReport()
+	assert.Equal(t, 0.75, report[0].SuccessRate)
+	assert.False(t, report[0].Breached)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Len(t, alerts, 2, "breach, then recovery")
+	assert.True(t, alerts[0].Breached)
+	assert.False(t, alerts[1].Breached)
+}
+
+func TestRegistrySLOLatency(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	registry := asynctask.NewRegistry()
+	registry.SetSLO("report", asynctask.SLO{Latency: 20 * time.Millisecond, LatencyRate: 0.9})
+	tasks := []*asynctask.TaskStatus{
+		asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithName("report")),
+		asynctask.Start(ctx, getCountingTask(5, 10*time.Millisecond), asynctask.WithName("report")),
+	}
+	registry.Track(tasks...)
+	assert.NoError(t, registry.Drain(ctx, nil))
+	assert.Eventually(t, func() bool { return registry.Len() == 0 }, time.Second, time.Millisecond)
+
+	report := registry.SLOReport()
+	assert.Equal(t, 1.0, report[0].SuccessRate)
+	assert.Equal(t, 0.5, report[0].LatencyRate)
+	assert.True(t, report[0].Breached)
+}
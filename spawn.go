@@ -0,0 +1,47 @@
+package asynctask
+
+import (
+	"context"
+	"strconv"
+)
+
+// Spawn start a sub-task from inside a task function, ctx should be the one passed to the function.
+// sub-task inherits from its parent:
+//   - name, as prefix: "parent/child" with WithName, "parent/N" (N-th spawned) otherwise
+//   - labels, unless overridden by WithLabel
+//   - event sink the parent was started with
+//   - pool, sub-task is submitted to the pool running the parent, mind a parent waiting on sub-tasks holds a worker
+//
+// ctx without a task behaves like Start.
+func Spawn(ctx context.Context, task AsyncFunc, opts ...TaskOption) *TaskStatus {
+	parent := taskFromContext(ctx)
+	if parent == nil {
+		return Start(ctx, task, opts...)
+	}
+
+	parent.mutex.Lock()
+	parent.spawned++
+	index := parent.spawned
+	parent.mutex.Unlock()
+
+	// applied last, so it sees name and labels set by opts.
+	opts = append(opts[:len(opts):len(opts)], func(o *taskOptions) {
+		o.parent = parent
+		name := o.name
+		if name == "" {
+			name = strconv.Itoa(index)
+		}
+		o.name = parent.options.name + "/" + name
+
+		for k, v := range parent.options.labels {
+			if _, ok := o.labels[k]; !ok {
+				WithLabel(k, v)(o)
+			}
+		}
+	})
+
+	if parent.pool != nil {
+		return parent.pool.Submit(ctx, task, opts...)
+	}
+	return Start(ctx, task, opts...)
+}
@@ -0,0 +1,75 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpawn(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	children := make(chan *asynctask.TaskStatus, 2)
+	parent := asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		first := asynctask.Spawn(fCtx, getCountingTask(3, time.Millisecond))
+		second := asynctask.Spawn(fCtx, getCountingTask(3, time.Millisecond), asynctask.WithName("fetch"), asynctask.WithLabel("stage", "fetch"))
+		children <- first
+		children <- second
+		return nil, asynctask.WaitAll(fCtx, &asynctask.WaitAllOptions{}, first, second)
+	}, asynctask.WithName("import"), asynctask.WithLabel("tenant", "contoso"), asynctask.WithLabel("stage", "root"))
+
+	_, err := parent.Wait(ctx)
+	assert.NoError(t, err)
+
+	info := (<-children).Info()
+	assert.Equal(t, "import/1", info.Name)
+	assert.Equal(t, map[string]string{"tenant": "contoso", "stage": "root"}, info.Labels)
+
+	info = (<-children).Info()
+	assert.Equal(t, "import/fetch", info.Name)
+	assert.Equal(t, map[string]string{"tenant": "contoso", "stage": "fetch"}, info.Labels)
+
+	// outside of a task, same as Start.
+	tsk := asynctask.Spawn(ctx, getCountingTask(3, time.Millisecond), asynctask.WithName("alone"))
+	_, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "alone", tsk.Info().Name)
+}
+
+func TestSpawnOnPool(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 2})
+	defer pool.Close()
+
+	parent := pool.Submit(ctx, func(fCtx context.Context) (interface{}, error) {
+		return asynctask.Spawn(fCtx, getCountingTask(3, time.Millisecond)).Wait(fCtx)
+	})
+	result, err := parent.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+	assert.Equal(t, uint64(2), pool.Stats().Started)
+
+	// canceling parent cancels sub-tasks through context.
+	spawned := make(chan *asynctask.TaskStatus, 1)
+	parent = pool.Submit(ctx, func(fCtx context.Context) (interface{}, error) {
+		child := asynctask.Spawn(fCtx, func(cCtx context.Context) (interface{}, error) {
+			<-cCtx.Done()
+			return nil, cCtx.Err()
+		})
+		spawned <- child
+		return child.Wait(fCtx)
+	})
+	child := <-spawned
+	parent.Cancel()
+	_, err = child.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, asynctask.StateFailed, child.State())
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import "context"
+
+// TypedGroup is a TaskGroup whose tasks all return R.
+type TypedGroup[R any] struct {
+	*TaskGroup
+}
+
+// StartAll start one task per input, running fn on it with opts shared by every task, and returns one handle for all of them.
+// task i works on inputs[i].
+func StartAll[T, R any](ctx context.Context, inputs []T, fn func(context.Context, T) (R, error), opts ...TaskOption) *TypedGroup[R] {
+	tasks := make([]*TaskStatus, 0, len(inputs))
+	for _, input := range inputs {
+		input := input
+		tasks = append(tasks, Start(ctx, func(fCtx context.Context) (interface{}, error) {
+			return fn(fCtx, input)
+		}, opts...))
+	}
+
+	return &TypedGroup[R]{TaskGroup: newTaskGroup(tasks, &groupOptions{})}
+}
+
+// Results block until all tasks finished, and returns outcome of each one, in order of inputs.
+// error is returned only if ctx is done first, task errors are in the results.
+func (g *TypedGroup[R]) Results(ctx context.Context) ([]Result[R], error) {
+	results := make([]Result[R], len(g.tasks))
+	for i, tsk := range g.tasks {
+		result, taskErr, waitErr := tsk.Await(ctx)
+		if waitErr != nil {
+			return nil, waitErr
+		}
+		value, _ := result.(R)
+		results[i] = Result[R]{Value: value, Err: taskErr}
+	}
+	return results, nil
+}
@@ -0,0 +1,48 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartAll(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartAll(ctx, []string{"1", "2", "x"}, func(ctx context.Context, s string) (int, error) {
+		time.Sleep(time.Millisecond)
+		return strconv.Atoi(s)
+	}, asynctask.WithLabel("batch", "parse"))
+	assert.Equal(t, 3, group.Len())
+	assert.Equal(t, map[string]string{"batch": "parse"}, group.Task(2).Info().Labels)
+
+	results, err := group.Results(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, 1, results[0].Value)
+	assert.Equal(t, 2, results[1].Value)
+	assert.False(t, results[2].Ok())
+	assert.Error(t, group.Wait(ctx, nil))
+	assert.Equal(t, asynctask.StateFailed, group.State())
+
+	// waiter gives up.
+	group = asynctask.StartAll(ctx, []string{"1"}, func(ctx context.Context, s string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	defer group.Cancel()
+	waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancelWait()
+	_, err = group.Results(waitCtx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+}
@@ -0,0 +1,73 @@
+package asynctask
+
+// stateChangesBuffer is number of states a channel of StateChanges holds for a reader who is behind,
+// retries can make a task go through more transitions than that, see notifySubscribers.
+const stateChangesBuffer = 8
+
+// StateChanges returns a channel receiving the current state of the task, followed by every state it transitions to.
+// channel is closed after the terminal state is delivered, so it can be used in select loops.
+// channel is buffered, if the reader falls too far behind, intermediate states are dropped,
+// but the terminal state and close are never missed.
+func (t *TaskStatus) StateChanges() <-chan State {
+	ch, _ := t.subscribe()
+	return ch
+}
+
+// subscribe is StateChanges, unsubscribe stops the channel from receiving states, without closing it.
+func (t *TaskStatus) subscribe() (changes <-chan State, unsubscribe func()) {
+	ch := make(chan State, stateChangesBuffer)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	ch <- t.state
+	if t.state.IsTerminalState() {
+		close(ch)
+		return ch, func() {}
+	}
+
+	t.subscribers = append(t.subscribers, ch)
+	return ch, func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		for i, subscriber := range t.subscribers {
+			if subscriber == ch {
+				t.subscribers = append(t.subscribers[:i], t.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notifySubscribers send state to subscribers, close them on terminal state.
+// caller should hold the lock.
+func (t *TaskStatus) notifySubscribers(state State) {
+	for _, ch := range t.subscribers {
+		if !state.IsTerminalState() {
+			select {
+			case ch <- state:
+			default:
+				// reader is behind, drop it rather than block the transition.
+			}
+			continue
+		}
+
+		// terminal state is always delivered, making room by dropping the oldest intermediate state.
+		for delivered := false; !delivered; {
+			select {
+			case ch <- state:
+				delivered = true
+			default:
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}
+		close(ch)
+	}
+
+	if state.IsTerminalState() {
+		t.subscribers = nil
+	}
+}
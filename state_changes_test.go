@@ -0,0 +1,59 @@
+package asynctask_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func collectStates(ch <-chan asynctask.State, timeout time.Duration) []asynctask.State {
+	var states []asynctask.State
+	for {
+		select {
+		case state, ok := <-ch:
+			if !ok {
+				return states
+			}
+			states = append(states, state)
+		case <-time.After(timeout):
+			return states
+		}
+	}
+}
+
+func TestStateChanges(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond))
+	changes := tsk.StateChanges()
+	tsk.Cancel()
+	assert.Equal(t, []asynctask.State{asynctask.StateRunning, asynctask.StateCanceled}, collectStates(changes, time.Second))
+
+	tsk = asynctask.Start(ctx, getCountingTask(2, time.Millisecond))
+	assert.Equal(t, []asynctask.State{asynctask.StateRunning, asynctask.StateCompleted}, collectStates(tsk.StateChanges(), time.Second))
+
+	// subscribe after finish, get terminal state and closed channel.
+	assert.Equal(t, []asynctask.State{asynctask.StateCompleted}, collectStates(tsk.StateChanges(), time.Second))
+}
+
+func TestStateChangesManyRetries(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// more transitions than the channel holds, reader catches up only once task is done.
+	tsk := asynctask.Start(ctx, getFailingTask(100, errors.New("always")),
+		asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 6, Backoff: time.Millisecond}))
+	changes := tsk.StateChanges()
+	_, err := tsk.Wait(ctx)
+	assert.Error(t, err)
+
+	states := collectStates(changes, time.Second)
+	assert.Len(t, states, 8)
+	assert.Equal(t, asynctask.StateFailed, states[len(states)-1])
+}
@@ -0,0 +1,40 @@
+package asynctask
+
+import "fmt"
+
+// stateTransitions lists states each state can move to, terminal states can't move at all.
+var stateTransitions = map[State][]State{
+	StateScheduled: {StateQueued, StateRunning, StateFailed, StateCanceled, StateSkipped},
+	StateQueued:    {StateRunning, StateFailed, StateCanceled},
+	StateRunning:   {StateCompleted, StateFailed, StateCanceled, StateRetrying, StatePaused},
+	StateRetrying:  {StateQueued, StateRunning, StateFailed, StateCanceled},
+	StatePaused:    {StateRunning, StateFailed, StateCanceled},
+}
+
+// canTransitionTo tells whether a task in state s can move to next.
+func (s State) canTransitionTo(next State) bool {
+	for _, allowed := range stateTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves the task to a non-terminal state, use finish for terminal ones.
+// it's an error to make a transition not listed in stateTransitions.
+func (t *TaskStatus) transition(next State) error {
+	if next.IsTerminalState() {
+		return fmt.Errorf("%s is terminal, use finish", next)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.state.canTransitionTo(next) {
+		return fmt.Errorf("invalid state transition from %s to %s", t.state, next)
+	}
+	t.state = next
+	t.notifySubscribers(next)
+	return nil
+}
@@ -0,0 +1,62 @@
+package asynctask
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateTransitions(t *testing.T) {
+	t.Parallel()
+
+	valid := [][2]State{
+		{StateQueued, StateRunning},
+		{StateRunning, StateRetrying},
+		{StateRetrying, StateQueued},
+		{StateRunning, StatePaused},
+		{StatePaused, StateRunning},
+		{StateRunning, StateCompleted},
+	}
+	for _, pair := range valid {
+		assert.True(t, pair[0].canTransitionTo(pair[1]), "%s -> %s should be allowed", pair[0], pair[1])
+	}
+
+	invalid := [][2]State{
+		{StateCanceled, StateCompleted},
+		{StateCompleted, StateFailed},
+		{StateFailed, StateRunning},
+		{StateQueued, StateCompleted},
+		{StateRunning, StateQueued},
+		{StateRunning, StateRunning},
+	}
+	for _, pair := range invalid {
+		assert.False(t, pair[0].canTransitionTo(pair[1]), "%s -> %s should be rejected", pair[0], pair[1])
+	}
+}
+
+func TestTaskTransition(t *testing.T) {
+	t.Parallel()
+
+	tsk := newRunningTask(func() {}, &taskOptions{})
+	changes := tsk.StateChanges()
+	assert.NoError(t, tsk.transition(StatePaused))
+	assert.NoError(t, tsk.transition(StateRunning))
+	assert.Error(t, tsk.transition(StateQueued))
+	assert.Error(t, tsk.transition(StateCompleted), "terminal state goes through finish")
+
+	tsk.finish(StateCanceled, nil, ErrCanceled)
+	// finish after terminal is ignored
+	tsk.finish(StateCompleted, "late result", nil)
+	assert.Equal(t, StateCanceled, tsk.State())
+	result, err := tsk.Wait(context.Background())
+	assert.Nil(t, result)
+	assert.Equal(t, ErrCanceled, err)
+	assert.Error(t, tsk.transition(StateRunning))
+
+	var states []State
+	for state := range changes {
+		states = append(states, state)
+	}
+	assert.Equal(t, []State{StateRunning, StatePaused, StateRunning, StateCanceled}, states)
+}
@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// StreamReceiver is the receive side of a server-streaming call,
+// it is satisfied by gRPC generated stream clients, with T being the pointer to response message.
+type StreamReceiver[T any] interface {
+	Recv() (T, error)
+}
+
+// StreamOpenFunc opens the stream, ctx passed in is owned by the task and get canceled with it.
+type StreamOpenFunc[T any] func(ctx context.Context) (StreamReceiver[T], error)
+
+// StreamTask is a handle to a task consuming a stream.
+type StreamTask struct {
+	// received is first field for 64-bit alignment of atomic access.
+	received int64
+	*TaskStatus
+}
+
+// Received returns number of messages delivered to the callback so far.
+func (s *StreamTask) Received() int64 {
+	return atomic.LoadInt64(&s.received)
+}
+
+// StartStream open a stream and run the receive loop as a task, each message is passed to onMessage in order.
+// task complete when stream ends with io.EOF, and the result is number of messages received.
+// error from Recv or onMessage fails the task, cancel the task (or ctx) cancels the stream.
+func StartStream[T any](ctx context.Context, open StreamOpenFunc[T], onMessage func(context.Context, T) error) *StreamTask {
+	stream := &StreamTask{}
+	stream.TaskStatus = Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		// stream is bound to this context, make sure it's released when we stop receiving.
+		fCtx, cancel := context.WithCancel(fCtx)
+		defer cancel()
+
+		receiver, err := open(fCtx)
+		if err != nil {
+			return int64(0), err
+		}
+
+		for {
+			msg, err := receiver.Recv()
+			if errors.Is(err, io.EOF) {
+				return stream.Received(), nil
+			}
+			if err != nil {
+				return stream.Received(), err
+			}
+
+			if err := onMessage(fCtx, msg); err != nil {
+				return stream.Received(), err
+			}
+			atomic.AddInt64(&stream.received, 1)
+		}
+	})
+
+	return stream
+}
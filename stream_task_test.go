@@ -0,0 +1,88 @@
+//go:build go1.18
+// +build go1.18
+
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMessage struct {
+	Seq int
+}
+
+type fakeStream struct {
+	ctx      context.Context
+	messages []*fakeMessage
+}
+
+func (s *fakeStream) Recv() (*fakeMessage, error) {
+	if len(s.messages) == 0 {
+		// block like a real stream until more messages or canceled.
+		<-s.ctx.Done()
+		return nil, s.ctx.Err()
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	if msg == nil {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func openFakeStream(messages ...*fakeMessage) asynctask.StreamOpenFunc[*fakeMessage] {
+	return func(ctx context.Context) (asynctask.StreamReceiver[*fakeMessage], error) {
+		return &fakeStream{ctx: ctx, messages: messages}, nil
+	}
+}
+
+func TestStartStream(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var seen []int
+	stream := asynctask.StartStream(ctx, openFakeStream(&fakeMessage{1}, &fakeMessage{2}, &fakeMessage{3}, nil),
+		func(ctx context.Context, msg *fakeMessage) error {
+			seen = append(seen, msg.Seq)
+			return nil
+		})
+
+	rawResult, err := stream.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rawResult)
+	assert.Equal(t, []int{1, 2, 3}, seen)
+	assert.Equal(t, int64(3), stream.Received())
+}
+
+func TestStartStreamFailureCase(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	stream := asynctask.StartStream(ctx, openFakeStream(&fakeMessage{1}, &fakeMessage{2}),
+		func(ctx context.Context, msg *fakeMessage) error {
+			if msg.Seq == 2 {
+				return errors.New("bad message")
+			}
+			return nil
+		})
+	_, err := stream.Wait(ctx)
+	assert.Equal(t, "bad message", err.Error())
+	assert.Equal(t, int64(1), stream.Received())
+
+	// stream blocks after first message, cancel should end it.
+	stream = asynctask.StartStream(ctx, openFakeStream(&fakeMessage{1}),
+		func(ctx context.Context, msg *fakeMessage) error { return nil })
+	time.Sleep(10 * time.Millisecond)
+	stream.Cancel()
+	_, err = stream.Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+}
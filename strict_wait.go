@@ -0,0 +1,75 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSelfWait is returned from Wait in strict mode, if the waiting task would wait on itself:
+// on its own handle, on a task waiting on it, or on a task stuck behind a mutex it holds (see WithMutex).
+var ErrSelfWait = errors.New("task waits on itself")
+
+var strictWait int32
+
+// SetStrictWait turns detection of waits which would deadlock on or off, it's off by default.
+// a wait is checked if its context belongs to a task, i.e. it's the context passed to the task function, or derived from it.
+// a task blocked behind a mutex held by a task waiting on it fails with ErrSelfWait as well.
+func SetStrictWait(enabled bool) {
+	value := int32(0)
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&strictWait, value)
+}
+
+// waitGraph guards who waits on whom: waitingOn and blockedOn of tasks, and holders of mutexes.
+var waitGraph sync.Mutex
+
+// beginWait record the task ctx belongs to waits on t, end should be called once the wait is over.
+// ErrSelfWait is returned if the wait closes a cycle, in strict mode.
+func (t *TaskStatus) beginWait(ctx context.Context) (end func(), err error) {
+	if atomic.LoadInt32(&strictWait) == 0 {
+		return func() {}, nil
+	}
+	waiter := taskFromContext(ctx)
+	if waiter == nil || t.State().IsTerminalState() {
+		return func() {}, nil
+	}
+
+	waitGraph.Lock()
+	defer waitGraph.Unlock()
+	if t.waitsFor(waiter, map[*TaskStatus]bool{}) {
+		return nil, ErrSelfWait
+	}
+	waiter.waitingOn = t
+	return func() {
+		waitGraph.Lock()
+		defer waitGraph.Unlock()
+		waiter.waitingOn = nil
+	}, nil
+}
+
+// waitsFor tells whether t is target, or waits on it, directly or not, waitGraph should be held.
+func (t *TaskStatus) waitsFor(target *TaskStatus, seen map[*TaskStatus]bool) bool {
+	if t == target {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	if t.waitingOn != nil && t.waitingOn.waitsFor(target, seen) {
+		return true
+	}
+	if t.blockedOn != nil {
+		for holder := range t.blockedOn.holders {
+			if holder.waitsFor(target, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package asynctask_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+// not parallel, it turns on strict mode for the process.
+func TestStrictWait(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+	asynctask.SetStrictWait(true)
+	defer asynctask.SetStrictWait(false)
+
+	// on its own handle.
+	self := make(chan *asynctask.TaskStatus, 1)
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return (<-self).Wait(ctx)
+	})
+	self <- tsk
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrSelfWait, err)
+
+	// a waits on b, which waits on a.
+	handleOfA := make(chan *asynctask.TaskStatus, 1)
+	bWaiting := make(chan struct{})
+	b := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		a := <-handleOfA
+		close(bWaiting)
+		return a.Wait(ctx)
+	})
+	a := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-bWaiting
+		// whichever of them waits last detects the cycle, the other one fails with its error.
+		return b.Wait(ctx)
+	})
+	handleOfA <- a
+	_, err = a.Wait(ctx)
+	assert.Equal(t, asynctask.ErrSelfWait, err)
+	_, err = b.Wait(ctx)
+	assert.Equal(t, asynctask.ErrSelfWait, err)
+
+	// waits on a task stuck behind a mutex it holds.
+	_, err = asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		inner := asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithMutex("strict-wait"))
+		return inner.Wait(ctx)
+	}, asynctask.WithMutex("strict-wait")).Wait(ctx)
+	assert.Equal(t, asynctask.ErrSelfWait, err)
+
+	// a healthy nested wait, and a wait from outside any task, are fine.
+	result, err := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return asynctask.Start(ctx, getCountingTask(2, time.Millisecond)).Wait(ctx)
+	}).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+}
+
+func TestStrictWaitOff(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	self := make(chan *asynctask.TaskStatus, 1)
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		return (<-self).Wait(waitCtx)
+	})
+	self <- tsk
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err, "wait isn't checked, it times out")
+}
@@ -0,0 +1,268 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrMaxFailures is the cause remaining tasks of a group get canceled with, once the group reached WithMaxFailures.
+var ErrMaxFailures = errors.New("group reached max failures")
+
+// TaskGroup is a handle to a batch of tasks started together,
+// which you can use to wait, cancel, or inspect them as a whole.
+type TaskGroup struct {
+	tasks []*TaskStatus
+
+	mutex sync.Mutex
+	// completed has outcome of finished tasks, in order they finished.
+	completed []TaskResult
+	failures  int
+	options   *groupOptions
+}
+
+// GroupOption configures a group at StartGroupWithOptions.
+type GroupOption func(*groupOptions)
+
+// groupOptions holds configuration of a group, collected from GroupOption.
+type groupOptions struct {
+	maxFailures int
+	concurrency int
+}
+
+// WithMaxFailures cancels remaining tasks of the group once n tasks failed, so a clearly broken batch fails fast.
+// remaining tasks finish with ErrCanceled, caused by ErrMaxFailures.
+func WithMaxFailures(n int) GroupOption {
+	return func(o *groupOptions) {
+		o.maxFailures = n
+	}
+}
+
+// WithGroupConcurrency allows at most k tasks of the group to run at the same time,
+// on top of any other limit, e.g. to model limit of a dependency at the call site.
+// like WithConcurrencyLimit, tasks wait for their turn before the function runs.
+func WithGroupConcurrency(k int) GroupOption {
+	return func(o *groupOptions) {
+		if k < 1 {
+			k = 1
+		}
+		o.concurrency = k
+	}
+}
+
+// StartGroup run each function as a task and returns you one handle for all of them.
+// context passed in may impact lifetime of every task in the group.
+func StartGroup(ctx context.Context, fns ...AsyncFunc) *TaskGroup {
+	return StartGroupWithOptions(ctx, nil, fns...)
+}
+
+// StartGroupWithOptions is StartGroup with options of the group.
+func StartGroupWithOptions(ctx context.Context, opts []GroupOption, fns ...AsyncFunc) *TaskGroup {
+	options := &groupOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var taskOpts []TaskOption
+	if options.concurrency > 0 {
+		limiter := make(chan struct{}, options.concurrency)
+		taskOpts = append(taskOpts, func(o *taskOptions) {
+			o.groupLimiter = limiter
+		})
+	}
+
+	tasks := make([]*TaskStatus, 0, len(fns))
+	for _, fn := range fns {
+		tasks = append(tasks, Start(ctx, fn, taskOpts...))
+	}
+
+	return newTaskGroup(tasks, options)
+}
+
+// newTaskGroup returns a group tracking tasks.
+func newTaskGroup(tasks []*TaskStatus, options *groupOptions) *TaskGroup {
+	g := &TaskGroup{tasks: tasks, options: options}
+	for i, tsk := range tasks {
+		i, tsk := i, tsk
+		tsk.onDone(func() {
+			g.track(i, tsk)
+		})
+	}
+	return g
+}
+
+// track record outcome of a finished task, and cancel the rest once max failures reached.
+func (g *TaskGroup) track(i int, tsk *TaskStatus) {
+	result, err := tsk.outcome()
+	g.mutex.Lock()
+	g.completed = append(g.completed, TaskResult{Index: i, Task: tsk, Result: result, Err: err})
+	if tsk.State() == StateFailed {
+		g.failures++
+	}
+	tooMany := g.options.maxFailures > 0 && g.failures == g.options.maxFailures
+	g.mutex.Unlock()
+
+	if tooMany {
+		for _, other := range g.tasks {
+			other.CancelWithCause(ErrMaxFailures)
+		}
+	}
+}
+
+// Len returns number of tasks in the group.
+func (g *TaskGroup) Len() int {
+	return len(g.tasks)
+}
+
+// Task returns the i-th task of the group, in the order functions were passed to StartGroup.
+func (g *TaskGroup) Task(i int) *TaskStatus {
+	return g.tasks[i]
+}
+
+// Tasks returns all tasks of the group, in the order functions were passed to StartGroup.
+func (g *TaskGroup) Tasks() []*TaskStatus {
+	tasks := make([]*TaskStatus, len(g.tasks))
+	copy(tasks, g.tasks)
+	return tasks
+}
+
+// Wait block current thread/routine until all tasks in the group finished.
+// first error from any task will be returned, see WaitAll for options.
+func (g *TaskGroup) Wait(ctx context.Context, options *WaitAllOptions) error {
+	if len(g.tasks) == 0 {
+		return nil
+	}
+	if options == nil {
+		options = &WaitAllOptions{}
+	}
+	return WaitAll(ctx, options, g.tasks...)
+}
+
+// CompletedSoFar returns outcome of tasks finished so far, in order they finished,
+// safe to call while other tasks run, e.g. to consume results incrementally.
+func (g *TaskGroup) CompletedSoFar() []TaskResult {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	completed := make([]TaskResult, len(g.completed))
+	copy(completed, g.completed)
+	return completed
+}
+
+// Cancel abort all tasks in the group which are not yet finished.
+func (g *TaskGroup) Cancel() {
+	for _, tsk := range g.tasks {
+		tsk.Cancel()
+	}
+}
+
+// Counts returns number of tasks in each state.
+func (g *TaskGroup) Counts() map[State]int {
+	counts := map[State]int{}
+	for _, tsk := range g.tasks {
+		counts[tsk.State()]++
+	}
+	return counts
+}
+
+// State summarize the group into one state:
+//   - Running if any task is not yet finished (queued, retrying etc. included)
+//   - Failed if any task failed
+//   - Canceled if any task got canceled
+//   - Completed otherwise, including empty group, and skipped tasks
+func (g *TaskGroup) State() State {
+	counts := g.Counts()
+	for state := range counts {
+		if !state.IsTerminalState() {
+			return StateRunning
+		}
+	}
+
+	switch {
+	case counts[StateFailed] > 0:
+		return StateFailed
+	case counts[StateCanceled] > 0:
+		return StateCanceled
+	default:
+		return StateCompleted
+	}
+}
+
+// TaskResult is the outcome of a task in a group.
+type TaskResult struct {
+	// Index of the task in the group.
+	Index  int
+	Task   *TaskStatus
+	Result interface{}
+	Err    error
+}
+
+// AsCompleted returns a channel delivering outcome of each task as soon as it finishes,
+// so fast tasks can be processed without waiting for the slowest one.
+// channel is closed after every task is delivered, or ctx is done.
+func (g *TaskGroup) AsCompleted(ctx context.Context) <-chan TaskResult {
+	finished := make(chan TaskResult, len(g.tasks))
+	for i, tsk := range g.tasks {
+		i, tsk := i, tsk
+		tsk.onDone(func() {
+			result, err := tsk.outcome()
+			finished <- TaskResult{Index: i, Task: tsk, Result: result, Err: err}
+		})
+	}
+
+	// buffered as well, so forwarding never blocks on a reader who left.
+	out := make(chan TaskResult, len(g.tasks))
+	go func() {
+		defer close(out)
+		for range g.tasks {
+			select {
+			case result := <-finished:
+				out <- result
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// GroupProgress is aggregate progress of a group.
+type GroupProgress struct {
+	// Finished is number of tasks in a terminal state, out of Total.
+	Finished int
+	Total    int
+	// Fraction is between 0 and 1, finished tasks count as 1, others as their last ReportProgress.
+	Fraction float64
+}
+
+// Progress returns aggregate progress of the group, empty group is done.
+func (g *TaskGroup) Progress() GroupProgress {
+	progress := GroupProgress{Total: len(g.tasks), Fraction: 1}
+	if len(g.tasks) == 0 {
+		return progress
+	}
+
+	sum := 0.0
+	for _, tsk := range g.tasks {
+		tsk.mutex.Lock()
+		if tsk.state.IsTerminalState() {
+			progress.Finished++
+			sum++
+		} else {
+			sum += tsk.progress
+		}
+		tsk.mutex.Unlock()
+	}
+	progress.Fraction = sum / float64(len(g.tasks))
+	return progress
+}
+
+// OnProgress register a callback receiving progress of the group each time one of its tasks finishes,
+// it runs on the routine which finished the task, see OnDone. poll Progress for changes in between.
+func (g *TaskGroup) OnProgress(callback func(GroupProgress)) {
+	for _, tsk := range g.tasks {
+		tsk.onDone(func() {
+			callback(g.Progress())
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskGroup(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartGroup(ctx,
+		getCountingTask(10, 2*time.Millisecond),
+		getCountingTask(10, 5*time.Millisecond),
+		getErrorTask("expected error", 10*time.Millisecond))
+	assert.Equal(t, 3, group.Len())
+	assert.Equal(t, asynctask.StateRunning, group.State())
+
+	err := group.Wait(ctx, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "expected error", err.Error())
+	assert.Equal(t, asynctask.StateFailed, group.State())
+	assert.Equal(t, map[asynctask.State]int{asynctask.StateCompleted: 2, asynctask.StateFailed: 1}, group.Counts())
+
+	rawResult, err := group.Task(1).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, rawResult)
+	assert.Len(t, group.Tasks(), 3)
+}
+
+func TestTaskGroupCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartGroup(ctx,
+		getCountingTask(10, 200*time.Millisecond),
+		getCountingTask(10, 200*time.Millisecond))
+	group.Cancel()
+	assert.Equal(t, asynctask.StateCanceled, group.State())
+	assert.Equal(t, asynctask.ErrCanceled, group.Wait(ctx, nil))
+
+	// empty group is done right away.
+	empty := asynctask.StartGroup(ctx)
+	assert.Equal(t, asynctask.StateCompleted, empty.State())
+	assert.NoError(t, empty.Wait(ctx, nil))
+}
+
+func TestTaskGroupAsCompleted(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartGroup(ctx,
+		getAdvancedCountingTask(100, 3, 20*time.Millisecond),
+		getErrorTask("expected error", 10*time.Millisecond),
+		getAdvancedCountingTask(0, 1, time.Millisecond))
+
+	var indexes []int
+	for result := range group.AsCompleted(ctx) {
+		indexes = append(indexes, result.Index)
+		switch result.Index {
+		case 0:
+			assert.Equal(t, 103, result.Result)
+		case 1:
+			assert.Equal(t, "expected error", result.Err.Error())
+		case 2:
+			assert.Equal(t, 1, result.Result)
+		}
+		assert.Equal(t, group.Task(result.Index), result.Task)
+	}
+	assert.Equal(t, []int{2, 1, 0}, indexes)
+}
+
+func TestTaskGroupAsCompletedCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartGroup(ctx,
+		getCountingTask(10, 200*time.Millisecond),
+		getAdvancedCountingTask(0, 1, time.Millisecond))
+	defer group.Cancel()
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelWait()
+	var results []asynctask.TaskResult
+	for result := range group.AsCompleted(waitCtx) {
+		results = append(results, result)
+	}
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Index)
+}
+
+func TestTaskGroupProgress(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	halfway := make(chan struct{})
+	finish := make(chan struct{})
+	group := asynctask.StartGroup(ctx,
+		getCountingTask(1, time.Millisecond),
+		func(ctx context.Context) (interface{}, error) {
+			asynctask.ReportProgress(ctx, 0.5)
+			close(halfway)
+			<-finish
+			return nil, nil
+		})
+	reports := make(chan asynctask.GroupProgress, 2)
+	group.OnProgress(func(progress asynctask.GroupProgress) {
+		reports <- progress
+	})
+
+	<-halfway
+	assert.Equal(t, 1, (<-reports).Finished)
+	assert.Equal(t, asynctask.GroupProgress{Finished: 1, Total: 2, Fraction: 0.75}, group.Progress())
+
+	close(finish)
+	assert.Equal(t, asynctask.GroupProgress{Finished: 2, Total: 2, Fraction: 1}, <-reports)
+	assert.Equal(t, asynctask.GroupProgress{Total: 0, Fraction: 1}, asynctask.StartGroup(ctx).Progress())
+}
+
+func TestTaskGroupCompletedSoFar(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	finish := make(chan struct{})
+	group := asynctask.StartGroup(ctx,
+		func(ctx context.Context) (interface{}, error) {
+			<-finish
+			return "slow", nil
+		},
+		getCountingTask(1, time.Millisecond),
+		getErrorTask("expected error", 10*time.Millisecond))
+	assert.Eventually(t, func() bool { return len(group.CompletedSoFar()) == 2 }, time.Second, time.Millisecond)
+
+	completed := group.CompletedSoFar()
+	assert.Equal(t, 1, completed[0].Index)
+	assert.Equal(t, 0, completed[0].Result)
+	assert.Equal(t, 2, completed[1].Index)
+	assert.Equal(t, "expected error", completed[1].Err.Error())
+	assert.Equal(t, asynctask.StateRunning, group.State())
+
+	close(finish)
+	assert.NoError(t, group.Task(0).WaitUntilState(ctx, asynctask.StateCompleted))
+	assert.Eventually(t, func() bool { return len(group.CompletedSoFar()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, "slow", group.CompletedSoFar()[2].Result)
+}
+
+func TestTaskGroupMaxFailures(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	group := asynctask.StartGroupWithOptions(ctx, []asynctask.GroupOption{asynctask.WithMaxFailures(2)},
+		getErrorTask("first error", time.Millisecond),
+		getErrorTask("second error", 10*time.Millisecond),
+		getCountingTask(10, 200*time.Millisecond),
+		getCountingTask(10, 200*time.Millisecond))
+
+	start := time.Now()
+	err := group.Wait(ctx, nil)
+	assert.Equal(t, "first error", err.Error())
+	assert.True(t, time.Since(start) < time.Second, "group should fail fast")
+	assert.Equal(t, map[asynctask.State]int{asynctask.StateFailed: 2, asynctask.StateCanceled: 2}, group.Counts())
+	_, err = group.Task(3).Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrMaxFailures), "expecting ErrMaxFailures")
+
+	// below threshold, rest keep running.
+	group = asynctask.StartGroupWithOptions(ctx, []asynctask.GroupOption{asynctask.WithMaxFailures(2)},
+		getErrorTask("only error", time.Millisecond),
+		getCountingTask(3, 5*time.Millisecond))
+	assert.Error(t, group.Wait(ctx, nil))
+	assert.Equal(t, map[asynctask.State]int{asynctask.StateFailed: 1, asynctask.StateCompleted: 1}, group.Counts())
+}
+
+func TestTaskGroupConcurrency(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	mutex := sync.Mutex{}
+	running, maxRunning := 0, 0
+	fns := make([]asynctask.AsyncFunc, 6)
+	for i := range fns {
+		fns[i] = func(ctx context.Context) (interface{}, error) {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return nil, nil
+		}
+	}
+
+	group := asynctask.StartGroupWithOptions(ctx, []asynctask.GroupOption{asynctask.WithGroupConcurrency(2)}, fns...)
+	assert.NoError(t, group.Wait(ctx, nil))
+	assert.Equal(t, 2, maxRunning)
+
+	// waiting for a slot ends with the task.
+	group = asynctask.StartGroupWithOptions(ctx, []asynctask.GroupOption{asynctask.WithGroupConcurrency(1)},
+		getCountingTask(10, 200*time.Millisecond),
+		getCountingTask(10, 200*time.Millisecond))
+	group.Task(1).Cancel()
+	group.Task(0).Cancel()
+	assert.Equal(t, asynctask.ErrCanceled, group.Wait(ctx, nil))
+}
@@ -0,0 +1,175 @@
+package asynctask
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// TaskInfo is a point-in-time snapshot of a task.
+type TaskInfo struct {
+	Name   string
+	Labels map[string]string
+	State  State
+	// Attempt is the current (or last) attempt of the task function, starting from 1, zero if never started.
+	Attempt int
+	// Progress is the last value reported through ReportProgress, between 0 and 1.
+	Progress   float64
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Err is the task error, nil if task is running or completed.
+	Err error
+	// ErrorClass is class of Err, see WithErrorClassifier.
+	ErrorClass ErrorClass
+	// Checkpoints is number of Checkpoint calls from the task function, a stuck task stops counting.
+	Checkpoints uint64
+	// CPUTime is CPU time the task function used, once it returned, zero unless started WithCPUTime.
+	CPUTime time.Duration
+}
+
+// QueueDuration returns how long the task waited before it started running, e.g. in a Pool queue.
+// if task never started, it's how long it has been waiting, or waited until it finished.
+func (i TaskInfo) QueueDuration() time.Duration {
+	switch {
+	case !i.StartedAt.IsZero():
+		return i.StartedAt.Sub(i.CreatedAt)
+	case i.FinishedAt.IsZero():
+		return time.Since(i.CreatedAt)
+	default:
+		return i.FinishedAt.Sub(i.CreatedAt)
+	}
+}
+
+// Duration returns how long the task has been running, or run for if it finished, queue time excluded.
+// zero if task never started.
+func (i TaskInfo) Duration() time.Duration {
+	switch {
+	case i.StartedAt.IsZero():
+		return 0
+	case i.FinishedAt.IsZero():
+		return time.Since(i.StartedAt)
+	default:
+		return i.FinishedAt.Sub(i.StartedAt)
+	}
+}
+
+// taskInfoJSON is the wire schema of TaskInfo, fields here should only be added, never renamed or removed.
+type taskInfoJSON struct {
+	Name        string            `json:"name,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	State       State             `json:"state"`
+	Attempt     int               `json:"attempt"`
+	Progress    float64           `json:"progress"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time        `json:"finishedAt,omitempty"`
+	QueueMs     int64             `json:"queueMs"`
+	DurationMs  int64             `json:"durationMs"`
+	Error       string            `json:"error,omitempty"`
+	ErrorClass  ErrorClass        `json:"errorClass,omitempty"`
+	Checkpoints uint64            `json:"checkpoints"`
+	CPUMs       int64             `json:"cpuMs,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, times are RFC 3339 and the error is its string.
+func (i TaskInfo) MarshalJSON() ([]byte, error) {
+	wire := taskInfoJSON{
+		Name:        i.Name,
+		Labels:      i.Labels,
+		State:       i.State,
+		Attempt:     i.Attempt,
+		Progress:    i.Progress,
+		CreatedAt:   i.CreatedAt,
+		QueueMs:     i.QueueDuration().Milliseconds(),
+		DurationMs:  i.Duration().Milliseconds(),
+		ErrorClass:  i.ErrorClass,
+		Checkpoints: i.Checkpoints,
+		CPUMs:       i.CPUTime.Milliseconds(),
+	}
+	if !i.StartedAt.IsZero() {
+		wire.StartedAt = &i.StartedAt
+	}
+	if !i.FinishedAt.IsZero() {
+		wire.FinishedAt = &i.FinishedAt
+	}
+	if i.Err != nil {
+		wire.Error = i.Err.Error()
+	}
+	return json.Marshal(wire)
+}
+
+// Info returns a snapshot of the task.
+func (t *TaskStatus) Info() TaskInfo {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return TaskInfo{
+		Name:        t.options.name,
+		Labels:      copyLabels(t.options.labels),
+		State:       t.state,
+		Attempt:     t.attempt,
+		Progress:    t.progress,
+		CreatedAt:   t.createdAt,
+		StartedAt:   t.startedAt,
+		FinishedAt:  t.finishedAt,
+		Err:         t.err,
+		ErrorClass:  t.errorClass,
+		Checkpoints: atomic.LoadUint64(&t.checkpoints),
+		CPUTime:     t.cpuTime,
+	}
+}
+
+// ReportProgress record progress (between 0 and 1) of the task running with ctx,
+// it's no-op if ctx doesn't belong to a task.
+func ReportProgress(ctx context.Context, progress float64) {
+	tsk := taskFromContext(ctx)
+	if tsk == nil {
+		return
+	}
+
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	tsk.mutex.Lock()
+	defer tsk.mutex.Unlock()
+	tsk.progress = progress
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
+
+// markStarted record start time, and move a Queued or Scheduled task to Running.
+func (t *TaskStatus) markStarted() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.startedAt = time.Now()
+	if t.state != StateRunning && t.state.canTransitionTo(StateRunning) {
+		t.state = StateRunning
+		t.notifySubscribers(StateRunning)
+	}
+}
+
+type taskContextKey struct{}
+
+// withTask returns a context carrying the task, for the task function to find itself.
+func withTask(ctx context.Context, tsk *TaskStatus) context.Context {
+	return context.WithValue(ctx, taskContextKey{}, tsk)
+}
+
+// taskFromContext returns the task ctx belongs to, nil if none.
+func taskFromContext(ctx context.Context) *TaskStatus {
+	tsk, _ := ctx.Value(taskContextKey{}).(*TaskStatus)
+	return tsk
+}
@@ -0,0 +1,81 @@
+package asynctask_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskInfo(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	reported := make(chan struct{})
+	proceed := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		asynctask.ReportProgress(ctx, 0.5)
+		close(reported)
+		<-proceed
+		asynctask.ReportProgress(ctx, 2)
+		return nil, nil
+	}, asynctask.WithName("export"))
+
+	<-reported
+	info := tsk.Info()
+	assert.Equal(t, "export", info.Name)
+	assert.Equal(t, asynctask.StateRunning, info.State)
+	assert.Equal(t, 0.5, info.Progress)
+	assert.False(t, info.StartedAt.IsZero())
+	assert.True(t, info.FinishedAt.IsZero())
+
+	close(proceed)
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	info = tsk.Info()
+	assert.Equal(t, asynctask.StateCompleted, info.State)
+	assert.Equal(t, 1.0, info.Progress, "progress should be capped at 1")
+	assert.False(t, info.FinishedAt.Before(info.StartedAt))
+
+	// no-op outside of a task.
+	asynctask.ReportProgress(ctx, 0.5)
+}
+
+func TestTaskInfoJSON(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2020, 10, 6, 0, 0, 0, 0, time.UTC)
+	info := asynctask.TaskInfo{
+		Name:       "export",
+		State:      asynctask.StateFailed,
+		Attempt:    2,
+		Progress:   0.25,
+		CreatedAt:  created,
+		StartedAt:  created.Add(time.Second),
+		FinishedAt: created.Add(3 * time.Second),
+		Err:        context.DeadlineExceeded,
+	}
+
+	data, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "export",
+		"state": "Failed",
+		"attempt": 2,
+		"progress": 0.25,
+		"createdAt": "2020-10-06T00:00:00Z",
+		"startedAt": "2020-10-06T00:00:01Z",
+		"finishedAt": "2020-10-06T00:00:03Z",
+		"queueMs": 1000,
+		"durationMs": 2000,
+		"error": "context deadline exceeded",
+		"checkpoints": 0
+	}`, string(data))
+
+	data, err = json.Marshal(asynctask.TaskInfo{State: asynctask.StateCanceled, CreatedAt: created, FinishedAt: created.Add(time.Second)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"state": "Canceled", "attempt": 0, "progress": 0, "createdAt": "2020-10-06T00:00:00Z", "finishedAt": "2020-10-06T00:00:01Z", "queueMs": 1000, "durationMs": 0, "checkpoints": 0}`, string(data))
+}
@@ -0,0 +1,44 @@
+package asynctask
+
+// Tee returns n sibling tasks of task, each going through its states and finishing with its outcome (state, result and error),
+// so independent consumers can each own a task with their own wait, timeout and cancel policy.
+// canceling a sibling only detaches it, task and other siblings keep running.
+func Tee(task *TaskStatus, n int) []*TaskStatus {
+	siblings := make([]*TaskStatus, 0, n)
+	options := newTaskOptions([]TaskOption{WithName(task.options.name)})
+	for i := 0; i < n; i++ {
+		changes, _ := task.subscribe()
+		// first state received is the current one.
+		state := <-changes
+		if state.IsTerminalState() {
+			result, err := task.outcome()
+			siblings = append(siblings, newFinishedTask(state, result, err, options))
+			continue
+		}
+
+		sibling := newRunningTask(func() {}, options)
+		sibling.state = state
+		go sibling.follow(task, changes)
+		siblings = append(siblings, sibling)
+	}
+	return siblings
+}
+
+// follow moves the task through states of source received on changes, and finish it with outcome of source.
+func (t *TaskStatus) follow(source *TaskStatus, changes <-chan State) {
+	for state := range changes {
+		if state.IsTerminalState() {
+			break
+		}
+		// a canceled sibling, or one which missed states of a busy source, stays where it is.
+		_ = t.transition(state)
+	}
+
+	state := source.State()
+	result, err := source.outcome()
+	if state == StateCompleted && t.State() != StateRunning {
+		// missed the Running state of source.
+		_ = t.transition(StateRunning)
+	}
+	t.finish(state, result, err)
+}
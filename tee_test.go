@@ -0,0 +1,85 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTee(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	finish := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		<-finish
+		return "config", nil
+	})
+	siblings := asynctask.Tee(tsk, 3)
+	assert.Len(t, siblings, 3)
+
+	// each sibling has its own wait policy, and canceling one doesn't affect others.
+	_, err := siblings[0].WaitWithTimeout(ctx, 5*time.Millisecond)
+	assert.Equal(t, asynctask.ErrWaitTimeout, err)
+	siblings[1].Cancel()
+	_, err = siblings[1].Wait(ctx)
+	assert.Equal(t, asynctask.ErrCanceled, err)
+	assert.Equal(t, asynctask.StateRunning, tsk.State())
+
+	close(finish)
+	for _, sibling := range []*asynctask.TaskStatus{siblings[0], siblings[2]} {
+		result, err := sibling.Wait(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "config", result)
+		assert.Equal(t, asynctask.StateCompleted, sibling.State())
+	}
+	assert.Equal(t, asynctask.StateCanceled, siblings[1].State())
+}
+
+func TestTeeFinishedTask(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	errBroken := errors.New("broken")
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, errBroken
+	})
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, errBroken, err)
+
+	for _, sibling := range asynctask.Tee(tsk, 2) {
+		_, err := sibling.Wait(ctx)
+		assert.Equal(t, errBroken, err)
+		assert.Equal(t, asynctask.StateFailed, sibling.State())
+	}
+}
+
+func TestTeeQueuedTask(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+
+	tsk := pool.Submit(ctx, getCountingTask(2, time.Millisecond))
+	sibling := asynctask.Tee(tsk, 1)[0]
+	// sibling is where task is, and follows it.
+	assert.Equal(t, asynctask.StateQueued, sibling.State())
+	changes := sibling.StateChanges()
+
+	release()
+	result, err := sibling.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	assert.Equal(t, []asynctask.State{
+		asynctask.StateQueued, asynctask.StateRunning, asynctask.StateCompleted,
+	}, collectStates(changes, time.Second))
+}
@@ -0,0 +1,62 @@
+package asynctask
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithTerminationProbe polls probe every interval while the task function runs, and completes the task (with nil result)
+// as soon as it returns true, even though the function is stuck, e.g. legacy blocking code whose outcome shows out of band:
+// a file appeared, a process exited. function context is canceled then, and whatever it returns later is ignored.
+// a panicking probe counts as false, see SetCallbackPanicHandler.
+func WithTerminationProbe(probe func() bool, interval time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.probe = probe
+		o.probeInterval = interval
+	}
+}
+
+// startProbe polls termination probe of the task, stop should be called once task function returned,
+// it waits for polling to end, and calling it again is no-op.
+func (t *TaskStatus) startProbe(ctx context.Context) (stop func()) {
+	if t.options.probe == nil || t.options.probeInterval <= 0 {
+		return func() {}
+	}
+
+	stopped := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(t.options.probeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stopped:
+				return
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-stopped:
+				// tick and stop came together.
+				return
+			default:
+			}
+
+			terminated := false
+			t.safeCall(func() { terminated = t.options.probe() })
+			if terminated {
+				t.finish(StateCompleted, nil, nil)
+				t.cancelFunc()
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() { close(stopped) })
+		<-exited
+	}
+}
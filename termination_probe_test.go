@@ -0,0 +1,56 @@
+package asynctask_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTerminationProbe(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	exited := int32(0)
+	release := make(chan struct{})
+	ctxErr := make(chan error, 1)
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		// legacy call, blind to ctx.
+		<-release
+		ctxErr <- ctx.Err()
+		return "late", nil
+	}, asynctask.WithTerminationProbe(func() bool {
+		return atomic.LoadInt32(&exited) == 1
+	}, 5*time.Millisecond))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, asynctask.StateRunning, tsk.State())
+	atomic.StoreInt32(&exited, 1)
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+
+	close(release)
+	assert.Equal(t, context.Canceled, <-ctxErr, "function context is canceled")
+	result, _ = tsk.Wait(ctx)
+	assert.Nil(t, result, "late result is ignored")
+
+	// function returning first wins, probe stops.
+	probes := int32(0)
+	result, err = asynctask.Start(ctx, getCountingTask(2, 5*time.Millisecond), asynctask.WithTerminationProbe(func() bool {
+		atomic.AddInt32(&probes, 1)
+		return false
+	}, time.Millisecond)).Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	probed := atomic.LoadInt32(&probes)
+	assert.Greater(t, probed, int32(0))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, probed, atomic.LoadInt32(&probes))
+}
@@ -0,0 +1,189 @@
+package asynctask
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithTimeout fails the task with ErrTimeout if it doesn't finish within d once started, retries included.
+// task context is done at that point, with context.DeadlineExceeded, and ExtendDeadline can push the deadline further.
+func WithTimeout(d time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.timeout = d
+	}
+}
+
+// ExtendDeadline grant the running task d more time, on top of its current deadline.
+// returns false if task wasn't started WithTimeout, isn't running yet, or already finished.
+func (t *TaskStatus) ExtendDeadline(d time.Duration) bool {
+	t.mutex.Lock()
+	timeout := t.timeout
+	t.mutex.Unlock()
+	if timeout == nil {
+		return false
+	}
+	return timeout.extend(d)
+}
+
+// startTimeout start the clock of a task started WithTimeout, and returns its context.
+// stop should be called once task function returned.
+func (t *TaskStatus) startTimeout(ctx context.Context) (timeoutCtx context.Context, stop func()) {
+	if t.options.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	c := newTimeoutContext(ctx, t.options.timeout, func() {
+		t.finish(StateFailed, nil, ErrTimeout)
+	})
+
+	t.mutex.Lock()
+	t.timeout = c
+	t.mutex.Unlock()
+
+	return c, c.stop
+}
+
+// startAttemptTimeout bounds an attempt of the task function retried as a whole by Timeouts.Attempt,
+// RunStage lifts the bound, attempts of its stages are bounded instead. stop should be called once the attempt returned.
+func (t *TaskStatus) startAttemptTimeout(ctx context.Context) (attemptCtx context.Context, stop func()) {
+	if t.options.attemptTimeout <= 0 || t.isStaged() {
+		return ctx, func() {}
+	}
+
+	c := newTimeoutContext(ctx, t.options.attemptTimeout, func() {})
+	t.mutex.Lock()
+	t.attemptTimeout = c
+	t.mutex.Unlock()
+	return c, c.stop
+}
+
+// liftAttemptTimeout lifts the bound of the running attempt, see startAttemptTimeout.
+func (t *TaskStatus) liftAttemptTimeout() {
+	t.mutex.Lock()
+	c := t.attemptTimeout
+	t.attemptTimeout = nil
+	t.mutex.Unlock()
+	if c != nil {
+		c.disarm()
+	}
+}
+
+// newTimeoutContext returns a context of ctx which expires after d, onExpire is invoked once it does.
+func newTimeoutContext(ctx context.Context, d time.Duration, onExpire func()) *timeoutContext {
+	c := &timeoutContext{
+		Context:  ctx,
+		done:     make(chan struct{}),
+		deadline: time.Now().Add(d),
+	}
+	// timer can fire right away, it's set before expire can run.
+	c.mutex.Lock()
+	c.timer = time.AfterFunc(d, func() {
+		if c.expire() {
+			onExpire()
+		}
+	})
+	c.mutex.Unlock()
+	c.stopParent = afterFunc(ctx, func() {
+		c.end(ctx.Err())
+	})
+	return c
+}
+
+// timeoutContext is a context with a deadline which can be extended, context.WithDeadline can't.
+// it has its own Done channel, so contexts derived from it see its Err, context.DeadlineExceeded once expired.
+type timeoutContext struct {
+	context.Context
+	stopParent func() bool
+	mutex      sync.Mutex
+	deadline   time.Time
+	timer      *time.Timer
+	done       chan struct{}
+	err        error
+	stopped    bool
+	// disarmed context never expires, it's done with its parent only.
+	disarmed bool
+}
+
+// Deadline implements context.Context, parent deadline wins if it's earlier.
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	c.mutex.Lock()
+	deadline, disarmed := c.deadline, c.disarmed
+	c.mutex.Unlock()
+
+	if disarmed {
+		return c.Context.Deadline()
+	}
+	if parent, ok := c.Context.Deadline(); ok && parent.Before(deadline) {
+		return parent, true
+	}
+	return deadline, true
+}
+
+// Done implements context.Context.
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err implements context.Context, it's set once Done is closed.
+func (c *timeoutContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+func (c *timeoutContext) extend(d time.Duration) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.err != nil || c.stopped {
+		return false
+	}
+	c.deadline = c.deadline.Add(d)
+	c.timer.Reset(time.Until(c.deadline))
+	return true
+}
+
+// expire ends the context if deadline passed, returns false if it was extended, stopped or ended meanwhile.
+func (c *timeoutContext) expire() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stopped || c.disarmed || time.Now().Before(c.deadline) {
+		// extended, timer was reset to fire again.
+		return false
+	}
+	return c.endLocked(context.DeadlineExceeded)
+}
+
+// end sets err and closes Done, returns false if context already ended.
+func (c *timeoutContext) end(err error) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.endLocked(err)
+}
+
+// endLocked is end with mutex held, err is set before Done is closed, so Err is never nil once Done is.
+func (c *timeoutContext) endLocked(err error) bool {
+	if c.err != nil {
+		return false
+	}
+	c.err = err
+	close(c.done)
+	c.timer.Stop()
+	return true
+}
+
+// disarm stops the clock, unless context already ended.
+func (c *timeoutContext) disarm() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.disarmed = true
+	c.timer.Stop()
+}
+
+func (c *timeoutContext) stop() {
+	c.stopParent()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stopped = true
+	c.endLocked(context.Canceled)
+}
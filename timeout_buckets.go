@@ -0,0 +1,78 @@
+package asynctask
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// minBucketWidth and maxBucketWidth bound how much a wait timeout may be rounded up to share a timer,
+	// see waitTimeouts.
+	minBucketWidth = time.Millisecond
+	maxBucketWidth = 50 * time.Millisecond
+)
+
+// waitTimeouts coalesce timeouts of WaitWithTimeout, so thousands of waiters with similar timeouts share few runtime timers.
+var waitTimeouts = &timeoutBuckets{origin: time.Now(), buckets: map[int64]*timeoutBucket{}}
+
+// timeoutBuckets group timeouts by deadline rounded up to a bucket, the first timeout of a bucket starts its timer,
+// and the last one which leaves before it expires stops it.
+// a timeout is rounded up by at most 1/64 of it (between 1ms and 50ms), it never expires early.
+type timeoutBuckets struct {
+	origin  time.Time
+	mutex   sync.Mutex
+	buckets map[int64]*timeoutBucket
+}
+
+type timeoutBucket struct {
+	// expired is closed once deadline of the bucket passed.
+	expired chan struct{}
+	timer   *time.Timer
+	waiters int
+}
+
+// acquire returns a channel closed once timeout elapsed, release should be called once caller stops waiting on it.
+func (b *timeoutBuckets) acquire(timeout time.Duration) (expired <-chan struct{}, release func()) {
+	width := timeout / 64
+	if width < minBucketWidth {
+		width = minBucketWidth
+	}
+	if width > maxBucketWidth {
+		width = maxBucketWidth
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	elapsed := time.Since(b.origin)
+	deadline := int64((elapsed+timeout+width-1)/width) * int64(width)
+	bucket, ok := b.buckets[deadline]
+	if !ok {
+		bucket = &timeoutBucket{expired: make(chan struct{})}
+		bucket.timer = time.AfterFunc(time.Duration(deadline)-elapsed, func() {
+			b.mutex.Lock()
+			if b.buckets[deadline] == bucket {
+				delete(b.buckets, deadline)
+			}
+			b.mutex.Unlock()
+			close(bucket.expired)
+		})
+		b.buckets[deadline] = bucket
+	}
+	bucket.waiters++
+
+	return bucket.expired, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		bucket.waiters--
+		if bucket.waiters == 0 && b.buckets[deadline] == bucket && bucket.timer.Stop() {
+			delete(b.buckets, deadline)
+		}
+	}
+}
+
+// len returns number of buckets waiting to expire.
+func (b *timeoutBuckets) len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.buckets)
+}
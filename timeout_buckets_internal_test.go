@@ -0,0 +1,63 @@
+package asynctask
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutBuckets(t *testing.T) {
+	t.Parallel()
+	buckets := &timeoutBuckets{origin: time.Now(), buckets: map[int64]*timeoutBucket{}}
+
+	// similar timeouts share a bucket, none expires early.
+	start := time.Now()
+	first, releaseFirst := buckets.acquire(200 * time.Millisecond)
+	second, releaseSecond := buckets.acquire(200 * time.Millisecond)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, buckets.len())
+	<-first
+	assert.True(t, time.Since(start) >= 200*time.Millisecond, "expired after %s", time.Since(start))
+	releaseFirst()
+	releaseSecond()
+	assert.Equal(t, 0, buckets.len())
+
+	// last waiter leaving stops the timer.
+	_, release := buckets.acquire(time.Hour)
+	assert.Equal(t, 1, buckets.len())
+	release()
+	assert.Equal(t, 0, buckets.len())
+}
+
+const benchmarkWaits = 10000
+
+// BenchmarkTimeoutBuckets is thousands of outstanding waits with similar timeouts, see BenchmarkContextTimeouts.
+func BenchmarkTimeoutBuckets(b *testing.B) {
+	buckets := &timeoutBuckets{origin: time.Now(), buckets: map[int64]*timeoutBucket{}}
+	releases := make([]func(), benchmarkWaits)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range releases {
+			_, releases[j] = buckets.acquire(time.Second)
+		}
+		for _, release := range releases {
+			release()
+		}
+	}
+}
+
+// BenchmarkContextTimeouts is BenchmarkTimeoutBuckets with a context timeout per wait, as WaitWithTimeout used to.
+func BenchmarkContextTimeouts(b *testing.B) {
+	cancels := make([]context.CancelFunc, benchmarkWaits)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range cancels {
+			_, cancels[j] = context.WithTimeout(context.Background(), time.Second)
+		}
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.Start(ctx, getCountingTask(3, time.Millisecond), asynctask.WithTimeout(time.Second))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+
+	taskErr := make(chan error, 1)
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		<-fCtx.Done()
+		taskErr <- fCtx.Err()
+		return nil, fCtx.Err()
+	}, asynctask.WithTimeout(20*time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrTimeout, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+	assert.Equal(t, context.DeadlineExceeded, <-taskErr)
+	assert.False(t, tsk.ExtendDeadline(time.Second), "can't extend finished task")
+
+	// contexts derived from task context expire as well.
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		derived, cancel := context.WithTimeout(fCtx, time.Hour)
+		defer cancel()
+		<-derived.Done()
+		taskErr <- derived.Err()
+		return nil, derived.Err()
+	}, asynctask.WithTimeout(20*time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrTimeout, err)
+	assert.Equal(t, context.DeadlineExceeded, <-taskErr)
+
+	// function ignoring context still times out.
+	release := make(chan struct{})
+	defer close(release)
+	tsk = asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	}, asynctask.WithTimeout(20*time.Millisecond))
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, asynctask.ErrTimeout, err)
+}
+
+func TestExtendDeadline(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	assert.False(t, asynctask.Start(ctx, getCountingTask(3, time.Millisecond)).ExtendDeadline(time.Second), "no timeout to extend")
+
+	deadlines := make(chan time.Time, 2)
+	extended := make(chan struct{})
+	tsk := asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		deadline, _ := fCtx.Deadline()
+		deadlines <- deadline
+		<-extended
+		// past the initial deadline.
+		time.Sleep(60 * time.Millisecond)
+		deadline, _ = fCtx.Deadline()
+		deadlines <- deadline
+		return "done", fCtx.Err()
+	}, asynctask.WithTimeout(40*time.Millisecond))
+
+	initial := <-deadlines
+	assert.True(t, tsk.ExtendDeadline(time.Second))
+	close(extended)
+
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", result)
+	assert.Equal(t, time.Second, (<-deadlines).Sub(initial))
+}
@@ -0,0 +1,94 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTimeouts is returned if Timeouts passed to WithTimeouts are not nested, i.e. attempt <= stage <= task.
+var ErrInvalidTimeouts = errors.New("invalid timeouts")
+
+// Timeouts are the three timeout layers of a task, zero means no timeout on that layer.
+type Timeouts struct {
+	// Task bounds the whole task, retries included, same as WithTimeout.
+	Task time.Duration
+	// Stage bounds each RunStage call of the task function, retries of the stage included.
+	Stage time.Duration
+	// Attempt bounds each attempt of a stage, or of the task function if it's retried as a whole.
+	Attempt time.Duration
+}
+
+// Validate checks layers are nested: attempt <= stage <= task, for layers which are set.
+func (t Timeouts) Validate() error {
+	if t.Task < 0 || t.Stage < 0 || t.Attempt < 0 {
+		return fmt.Errorf("%w: negative timeout %+v", ErrInvalidTimeouts, t)
+	}
+
+	// compare every set inner layer with every set outer layer.
+	layers := []struct {
+		name    string
+		timeout time.Duration
+	}{{"attempt", t.Attempt}, {"stage", t.Stage}, {"task", t.Task}}
+	for i, inner := range layers {
+		for _, outer := range layers[i+1:] {
+			if inner.timeout > 0 && outer.timeout > 0 && inner.timeout > outer.timeout {
+				return fmt.Errorf("%w: %s timeout %s exceeds %s timeout %s", ErrInvalidTimeouts, inner.name, inner.timeout, outer.name, outer.timeout)
+			}
+		}
+	}
+	return nil
+}
+
+// WithTimeouts configures all timeout layers of the task, see Timeouts and RunStage.
+// task with invalid timeouts fails right away with ErrInvalidTimeouts, without running.
+func WithTimeouts(timeouts Timeouts) TaskOption {
+	return func(o *taskOptions) {
+		if err := timeouts.Validate(); err != nil {
+			o.err = err
+			return
+		}
+		o.timeout = timeouts.Task
+		o.stageTimeout = timeouts.Stage
+		o.attemptTimeout = timeouts.Attempt
+	}
+}
+
+// RunStage runs a stage of the task function, ctx should be the one passed to the function.
+// stage is bounded by Timeouts.Stage, and retried as the task RetryPolicy allows, each attempt bounded by Timeouts.Attempt.
+// task goes through StateRetrying between attempts, like a task retried as a whole.
+// task function using stages is not retried as a whole, its stages are, nor is its attempt bounded by Timeouts.Attempt.
+// ctx without a task runs the stage once, with no timeout.
+func RunStage(ctx context.Context, stage func(context.Context) error) error {
+	tsk := taskFromContext(ctx)
+	if tsk == nil {
+		return stage(ctx)
+	}
+
+	tsk.mutex.Lock()
+	tsk.staged = true
+	tsk.mutex.Unlock()
+	tsk.liftAttemptTimeout()
+
+	options := tsk.options
+	if options.stageTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.stageTimeout)
+		defer cancel()
+	}
+
+	_, err := tsk.retry(ctx, options.retry, tsk.shouldRetry, func(ctx context.Context, attempt int) (interface{}, error) {
+		return nil, runAttempt(ctx, options.attemptTimeout, stage)
+	})
+	return err
+}
+
+func runAttempt(ctx context.Context, timeout time.Duration, stage func(context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return stage(ctx)
+}
@@ -0,0 +1,129 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := []asynctask.Timeouts{
+		{},
+		{Task: time.Second},
+		{Task: time.Second, Stage: time.Second, Attempt: time.Second},
+		{Task: time.Second, Attempt: 100 * time.Millisecond},
+		{Stage: time.Second, Attempt: 100 * time.Millisecond},
+	}
+	for _, timeouts := range valid {
+		assert.NoError(t, timeouts.Validate(), "%+v", timeouts)
+	}
+
+	invalid := []asynctask.Timeouts{
+		{Task: time.Second, Stage: 2 * time.Second},
+		{Stage: time.Second, Attempt: 2 * time.Second},
+		{Task: time.Second, Attempt: 2 * time.Second},
+		{Task: -time.Second},
+	}
+	for _, timeouts := range invalid {
+		assert.True(t, errors.Is(timeouts.Validate(), asynctask.ErrInvalidTimeouts), "%+v", timeouts)
+	}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// invalid timeouts fail the task without running it.
+	var ran int32
+	tsk := asynctask.Start(ctx, func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&ran, 1)
+		return nil, nil
+	}, asynctask.WithTimeouts(asynctask.Timeouts{Task: time.Millisecond, Stage: time.Second}))
+	_, err := tsk.Wait(ctx)
+	assert.True(t, errors.Is(err, asynctask.ErrInvalidTimeouts), "expecting ErrInvalidTimeouts")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&ran))
+
+	// slow attempts time out and get retried within the stage.
+	var attempts int32
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		err := asynctask.RunStage(fCtx, func(sCtx context.Context) error {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				<-sCtx.Done()
+				return sCtx.Err()
+			}
+			return nil
+		})
+		return atomic.LoadInt32(&attempts), err
+	}, asynctask.WithTimeouts(asynctask.Timeouts{Task: time.Second, Stage: 500 * time.Millisecond, Attempt: 10 * time.Millisecond}),
+		asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 5}))
+	result, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), result)
+
+	// stage timeout cuts retries short.
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		return nil, asynctask.RunStage(fCtx, func(sCtx context.Context) error {
+			<-sCtx.Done()
+			return sCtx.Err()
+		})
+	}, asynctask.WithTimeouts(asynctask.Timeouts{Task: time.Second, Stage: 50 * time.Millisecond, Attempt: 20 * time.Millisecond}),
+		asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 100}))
+	start := time.Now()
+	_, err = tsk.Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+
+	// hanging attempt of a task retried as a whole times out and gets retried.
+	attempts = 0
+	tsk = asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-fCtx.Done()
+			return nil, fCtx.Err()
+		}
+		return atomic.LoadInt32(&attempts), nil
+	}, asynctask.WithTimeouts(asynctask.Timeouts{Task: time.Second, Attempt: 20 * time.Millisecond}),
+		asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 2}))
+	result, err = tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), result)
+
+	// outside a task, stage runs once.
+	assert.NoError(t, asynctask.RunStage(ctx, func(context.Context) error { return nil }))
+}
+
+func TestRunStageRetryObserved(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	// stage retries go through StateRetrying, and count attempts like retries of the whole task.
+	start := make(chan struct{})
+	var attempts int32
+	tsk := asynctask.Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		<-start
+		return nil, asynctask.RunStage(fCtx, func(sCtx context.Context) error {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				return errors.New("flaky")
+			}
+			return nil
+		})
+	}, asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	changes := tsk.StateChanges()
+	close(start)
+
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tsk.Info().Attempt)
+	assert.Equal(t, []asynctask.State{
+		asynctask.StateRunning, asynctask.StateRetrying, asynctask.StateRunning,
+		asynctask.StateRetrying, asynctask.StateRunning, asynctask.StateCompleted,
+	}, collectStates(changes, time.Second))
+}
@@ -0,0 +1,202 @@
+package asynctask
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// wheelTick is resolution of the scheduler timer wheel, a timer fires within one tick after its delay.
+	wheelTick = time.Millisecond
+	// wheelSlots is number of slots of the scheduler timer wheel, one turn of the wheel is wheelSlots ticks.
+	wheelSlots = 1024
+)
+
+// schedulerWheel holds timers of scheduled tasks, see StartAfter.
+var schedulerWheel = newTimerWheel(wheelTick, wheelSlots)
+
+// timerWheel is a hashed timer wheel: timers are hashed by their due tick into slots, longer delays wait
+// some rounds of the wheel. one routine drives the wheel while it has timers, sleeping until the next
+// non empty slot, so tens of thousands of timers cost a slice entry each, instead of a runtime timer.
+type timerWheel struct {
+	tick   time.Duration
+	origin time.Time
+
+	mutex sync.Mutex
+	slots [][]*wheelTimer
+	// processed is the last tick whose slot was processed, ticks are counted from origin.
+	processed int64
+	count     int
+	running   bool
+	// wakeAt is the tick the driving routine sleeps until, wake interrupts the sleep for an earlier timer.
+	wakeAt int64
+	wake   chan struct{}
+}
+
+// wheelTimer is a timer of a timerWheel, see timerWheel.afterFunc.
+type wheelTimer struct {
+	wheel *timerWheel
+	fn    func()
+	slot  int
+	// rounds is number of times the slot comes around before the timer is due.
+	rounds int64
+	// pending is true until the timer fired or got stopped, guarded by mutex of the wheel.
+	pending bool
+}
+
+func newTimerWheel(tick time.Duration, slots int) *timerWheel {
+	return &timerWheel{
+		tick:   tick,
+		origin: time.Now(),
+		slots:  make([][]*wheelTimer, slots),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// now returns number of whole ticks elapsed since origin.
+func (w *timerWheel) now() int64 {
+	return int64(time.Since(w.origin) / w.tick)
+}
+
+// afterFunc run fn on its own routine once delay elapsed, like time.AfterFunc, never earlier than delay.
+func (w *timerWheel) afterFunc(delay time.Duration, fn func()) *wheelTimer {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	elapsed := time.Since(w.origin)
+	if !w.running {
+		// wheel was idle, no slot is behind.
+		w.processed = int64(elapsed / w.tick)
+	}
+	// round up, the due tick is the first one after elapsed+delay.
+	due := int64((elapsed+delay)/w.tick) + 1
+	if due <= w.processed {
+		due = w.processed + 1
+	}
+
+	n := int64(len(w.slots))
+	timer := &wheelTimer{
+		wheel:   w,
+		fn:      fn,
+		slot:    int(due % n),
+		rounds:  (due - w.processed - 1) / n,
+		pending: true,
+	}
+	w.slots[timer.slot] = append(w.slots[timer.slot], timer)
+	w.count++
+
+	switch {
+	case !w.running:
+		w.running = true
+		w.wakeAt = due
+		go w.run()
+	case due < w.wakeAt:
+		w.wakeAt = due
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return timer
+}
+
+// Stop prevents the timer from firing, returns false if it already fired or got stopped.
+func (t *wheelTimer) Stop() bool {
+	w := t.wheel
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !t.pending {
+		return false
+	}
+	t.pending = false
+	slot := w.slots[t.slot]
+	for i, other := range slot {
+		if other == t {
+			last := len(slot) - 1
+			slot[i] = slot[last]
+			slot[last] = nil
+			w.slots[t.slot] = slot[:last]
+			break
+		}
+	}
+	w.count--
+	return true
+}
+
+// run drives the wheel until it has no timer left.
+func (w *timerWheel) run() {
+	sleep := time.NewTimer(0)
+	defer sleep.Stop()
+	for {
+		w.mutex.Lock()
+		fired := w.advance(w.now())
+		if w.count == 0 {
+			w.running = false
+			w.mutex.Unlock()
+			fireTimers(fired)
+			return
+		}
+		w.wakeAt = w.processed + w.nextDue()
+		wait := w.origin.Add(time.Duration(w.wakeAt) * w.tick).Sub(time.Now())
+		w.mutex.Unlock()
+		fireTimers(fired)
+
+		if !sleep.Stop() {
+			select {
+			case <-sleep.C:
+			default:
+			}
+		}
+		sleep.Reset(wait)
+		select {
+		case <-sleep.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// advance process slots of ticks up to now, and returns timers which are due, mutex should be held.
+func (w *timerWheel) advance(now int64) (fired []*wheelTimer) {
+	n := int64(len(w.slots))
+	for ; w.processed < now && w.count > 0; w.processed++ {
+		index := (w.processed + 1) % n
+		slot := w.slots[index]
+		kept := slot[:0]
+		for _, timer := range slot {
+			if timer.rounds > 0 {
+				timer.rounds--
+				kept = append(kept, timer)
+				continue
+			}
+			timer.pending = false
+			fired = append(fired, timer)
+		}
+		for i := len(kept); i < len(slot); i++ {
+			slot[i] = nil
+		}
+		w.slots[index] = kept
+		w.count -= len(slot) - len(kept)
+	}
+	if w.processed < now {
+		w.processed = now
+	}
+	return fired
+}
+
+// nextDue returns number of ticks after processed until the next non empty slot, mutex should be held.
+func (w *timerWheel) nextDue() int64 {
+	n := int64(len(w.slots))
+	for ahead := int64(1); ahead < n; ahead++ {
+		if len(w.slots[(w.processed+ahead)%n]) > 0 {
+			return ahead
+		}
+	}
+	return n
+}
+
+// fireTimers run function of each timer on its own routine, like time.AfterFunc does.
+func fireTimers(timers []*wheelTimer) {
+	for _, timer := range timers {
+		go timer.fn()
+	}
+}
@@ -0,0 +1,115 @@
+package asynctask
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	t.Parallel()
+	wheel := newTimerWheel(time.Millisecond, 8)
+
+	// delays beyond one turn of the wheel wait extra rounds, no timer fires early.
+	delays := []time.Duration{0, 3 * time.Millisecond, 20 * time.Millisecond, 7 * time.Millisecond, 45 * time.Millisecond}
+	var wg sync.WaitGroup
+	start := time.Now()
+	fired := make([]time.Duration, len(delays))
+	for i, delay := range delays {
+		i := i
+		wg.Add(1)
+		wheel.afterFunc(delay, func() {
+			fired[i] = time.Since(start)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	for i, delay := range delays {
+		assert.True(t, fired[i] >= delay, "timer %d fired after %s, before its delay %s", i, fired[i], delay)
+	}
+
+	// wheel stops its routine once empty, and starts again.
+	assert.Eventually(t, func() bool {
+		wheel.mutex.Lock()
+		defer wheel.mutex.Unlock()
+		return !wheel.running
+	}, time.Second, time.Millisecond)
+	done := make(chan struct{})
+	wheel.afterFunc(2*time.Millisecond, func() { close(done) })
+	<-done
+}
+
+func TestTimerWheelStop(t *testing.T) {
+	t.Parallel()
+	wheel := newTimerWheel(time.Millisecond, 8)
+
+	stopped := wheel.afterFunc(10*time.Millisecond, func() { t.Error("stopped timer fired") })
+	done := make(chan struct{})
+	fired := wheel.afterFunc(15*time.Millisecond, func() { close(done) })
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	<-done
+	assert.False(t, fired.Stop())
+	time.Sleep(10 * time.Millisecond)
+}
+
+const benchmarkTimers = 50000
+
+// BenchmarkTimerWheel schedules and stops tens of thousands of timers on the wheel, see BenchmarkAfterFunc.
+func BenchmarkTimerWheel(b *testing.B) {
+	wheel := newTimerWheel(time.Millisecond, wheelSlots)
+	timers := make([]*wheelTimer, benchmarkTimers)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range timers {
+			timers[j] = wheel.afterFunc(time.Duration(j%3600)*time.Second, func() {})
+		}
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}
+}
+
+// BenchmarkAfterFunc is BenchmarkTimerWheel with a runtime timer per task, as StartAfter used to.
+func BenchmarkAfterFunc(b *testing.B) {
+	timers := make([]*time.Timer, benchmarkTimers)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range timers {
+			timers[j] = time.AfterFunc(time.Duration(j%3600)*time.Second, func() {})
+		}
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}
+}
+
+// BenchmarkTimerWheelFire fires tens of thousands of short timers on the wheel, see BenchmarkAfterFuncFire.
+func BenchmarkTimerWheelFire(b *testing.B) {
+	wheel := newTimerWheel(time.Millisecond, wheelSlots)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(benchmarkTimers)
+		for j := 0; j < benchmarkTimers; j++ {
+			wheel.afterFunc(time.Duration(j%10)*time.Millisecond, wg.Done)
+		}
+		wg.Wait()
+	}
+}
+
+// BenchmarkAfterFuncFire is BenchmarkTimerWheelFire with a runtime timer per task.
+func BenchmarkAfterFuncFire(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(benchmarkTimers)
+		for j := 0; j < benchmarkTimers; j++ {
+			time.AfterFunc(time.Duration(j%10)*time.Millisecond, wg.Done)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,105 @@
+package asynctask
+
+import (
+	"math/rand"
+	"time"
+)
+
+// TaskTrace is detailed timing of a task, recorded for a sample of tasks, see WithTraceSampling.
+type TaskTrace struct {
+	// Queue is time from creation until a routine picked the task up, e.g. a pool worker.
+	Queue time.Duration
+	// Start is time from then until the function first ran: waiting for concurrency limits and mutexes included.
+	Start    time.Duration
+	Attempts []AttemptTrace
+	// Callbacks is time OnDone callbacks of the task took, once it finished.
+	Callbacks time.Duration
+}
+
+// AttemptTrace is timing of one attempt of the task function.
+type AttemptTrace struct {
+	Attempt  int
+	Duration time.Duration
+	Err      error
+}
+
+// TraceSink is an EventSink which also receives traces of sampled tasks, once their callbacks ran.
+type TraceSink interface {
+	EventSink
+	OnTrace(tsk *TaskStatus, trace TaskTrace)
+}
+
+// WithTraceSampling records a TaskTrace for a fraction rate (between 0 and 1) of tasks started with it,
+// delivered to the event sink if it's a TraceSink, for deep diagnostics without the overhead of tracing everything.
+func WithTraceSampling(rate float64) TaskOption {
+	return func(o *taskOptions) {
+		o.traceRate = rate
+	}
+}
+
+// taskTrace is the trace being recorded, guarded by mutex of the task.
+type taskTrace struct {
+	TaskTrace
+	pickedUp time.Time
+}
+
+// sampleTrace decide whether a new task is traced.
+func sampleTrace(options *taskOptions) *taskTrace {
+	if options.traceRate <= 0 || rand.Float64() >= options.traceRate {
+		return nil
+	}
+	return &taskTrace{}
+}
+
+// tracePickedUp record the task routine picked the task up.
+func (t *TaskStatus) tracePickedUp() {
+	if t.trace == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.trace.pickedUp = time.Now()
+	t.trace.Queue = t.trace.pickedUp.Sub(t.createdAt)
+}
+
+// traceStarted record the task function is about to run.
+func (t *TaskStatus) traceStarted() {
+	if t.trace == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.trace.Start = time.Since(t.trace.pickedUp)
+}
+
+// traceAttempt record an attempt of the task function, which started at start.
+func (t *TaskStatus) traceAttempt(attempt int, start time.Time, err error) {
+	if t.trace == nil {
+		return
+	}
+	if err != nil && !isErrorReallyError(err) {
+		err = nil
+	}
+	d := time.Since(start)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.trace.Attempts = append(t.trace.Attempts, AttemptTrace{Attempt: attempt, Duration: d, Err: err})
+}
+
+// emitTrace record time callbacks took, and deliver the trace to the sink.
+func (t *TaskStatus) emitTrace(callbacks time.Duration) {
+	if t.trace == nil {
+		return
+	}
+	sink, ok := t.sink.(TraceSink)
+	if !ok {
+		return
+	}
+	t.mutex.Lock()
+	t.trace.Callbacks = callbacks
+	trace := t.trace.TaskTrace
+	trace.Attempts = append([]AttemptTrace(nil), trace.Attempts...)
+	t.mutex.Unlock()
+
+	t.safeCall(func() { sink.OnTrace(t, trace) })
+}
@@ -0,0 +1,98 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+type traceRecorder struct {
+	asynctask.NopEventSink
+	mutex  sync.Mutex
+	traces map[string]asynctask.TaskTrace
+}
+
+func (r *traceRecorder) OnTrace(tsk *asynctask.TaskStatus, trace asynctask.TaskTrace) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.traces[tsk.Info().Name] = trace
+}
+
+func (r *traceRecorder) get(name string) (asynctask.TaskTrace, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	trace, ok := r.traces[name]
+	return trace, ok
+}
+
+// not parallel, it sets the global sink.
+func TestWithTraceSampling(t *testing.T) {
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+	recorder := &traceRecorder{traces: map[string]asynctask.TaskTrace{}}
+	asynctask.SetEventSink(recorder)
+	defer asynctask.SetEventSink(nil)
+
+	errFlaky := errors.New("flaky")
+	attempts := 0
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		time.Sleep(5 * time.Millisecond)
+		if attempts == 1 {
+			return nil, errFlaky
+		}
+		return "done", nil
+	}, asynctask.WithName("traced"), asynctask.WithTraceSampling(1),
+		asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}))
+	tsk.OnDone(func(interface{}, error) { time.Sleep(5 * time.Millisecond) })
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, ok := recorder.get("traced")
+		return ok
+	}, time.Second, time.Millisecond)
+
+	trace, _ := recorder.get("traced")
+	assert.GreaterOrEqual(t, int64(trace.Queue), int64(0))
+	assert.GreaterOrEqual(t, int64(trace.Start), int64(0))
+	assert.Len(t, trace.Attempts, 2)
+	assert.Equal(t, 1, trace.Attempts[0].Attempt)
+	assert.Equal(t, errFlaky, trace.Attempts[0].Err)
+	assert.GreaterOrEqual(t, int64(trace.Attempts[0].Duration), int64(5*time.Millisecond))
+	assert.NoError(t, trace.Attempts[1].Err)
+	assert.GreaterOrEqual(t, int64(trace.Callbacks), int64(5*time.Millisecond))
+
+	// queue time of a pool task.
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	block := make(chan struct{})
+	pool.Submit(ctx, func(ctx context.Context) (interface{}, error) {
+		<-block
+		return nil, nil
+	})
+	queued := pool.Submit(ctx, getCountingTask(1, time.Millisecond), asynctask.WithName("queued"), asynctask.WithTraceSampling(1))
+	time.Sleep(20 * time.Millisecond)
+	close(block)
+	_, err = queued.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		trace, ok := recorder.get("queued")
+		return ok && trace.Queue >= 20*time.Millisecond
+	}, time.Second, time.Millisecond)
+
+	// never sampled.
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithName("untraced"), asynctask.WithTraceSampling(0)).Wait(ctx)
+	assert.NoError(t, err)
+	_, err = asynctask.Start(ctx, getCountingTask(1, time.Millisecond), asynctask.WithName("default")).Wait(ctx)
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, ok := recorder.get("untraced")
+	assert.False(t, ok)
+	_, ok = recorder.get("default")
+	assert.False(t, ok)
+}
@@ -66,7 +66,7 @@ func WaitAll(ctx context.Context, options *WaitAllOptions, tasks ...*TaskStatus)
 }
 
 func waitOne(ctx context.Context, tsk *TaskStatus, errorCh chan<- error, errorChClosed *bool, mutex *sync.Mutex) {
-	_, err := tsk.Wait(ctx)
+	_, err := tsk.waitFinished(ctx)
 
 	// why mutex?
 	// if all tasks start using same context (unittest is good example)
@@ -0,0 +1,75 @@
+package asynctask
+
+import (
+	"context"
+	"time"
+)
+
+// ConditionFunc reports whether a condition WaitFor is waiting on has become true.
+type ConditionFunc func(context.Context) (bool, error)
+
+// WaitForOptions defines options for WaitFor function
+type WaitForOptions struct {
+	// Interval is the wait between checks, zero means no polling.
+	// default to 1 second if Recheck is not set either.
+	Interval time.Duration
+	// Recheck triggers a check each time it receives, in addition to polling.
+	// a closed Recheck channel is ignored from then on.
+	Recheck <-chan struct{}
+}
+
+const defaultWaitForInterval = time.Second
+
+// WaitFor start a task which complete once condition becomes true, error from condition fails the task.
+// condition is checked right away, then on every poll interval and every Recheck event.
+func WaitFor(ctx context.Context, condition ConditionFunc, options *WaitForOptions) *TaskStatus {
+	if options == nil {
+		options = &WaitForOptions{}
+	}
+	interval := options.Interval
+	if interval <= 0 && options.Recheck == nil {
+		interval = defaultWaitForInterval
+	}
+
+	return Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		recheck := options.Recheck
+
+		for {
+			ok, err := condition(fCtx)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return nil, nil
+			}
+
+			if !waitForRecheck(fCtx, tick, &recheck) {
+				return nil, fCtx.Err()
+			}
+		}
+	})
+}
+
+// waitForRecheck blocks until next tick or recheck event, returns false if ctx is done.
+func waitForRecheck(ctx context.Context, tick <-chan time.Time, recheck *<-chan struct{}) bool {
+	for {
+		select {
+		case <-tick:
+			return true
+		case _, open := <-*recheck:
+			if open {
+				return true
+			}
+			// nil channel blocks forever, stop listening on the closed one.
+			*recheck = nil
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForPolling(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var checks int32
+	tsk := asynctask.WaitFor(ctx, func(ctx context.Context) (bool, error) {
+		return atomic.AddInt32(&checks, 1) >= 3, nil
+	}, &asynctask.WaitForOptions{Interval: time.Millisecond})
+
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&checks))
+}
+
+func TestWaitForRecheck(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	var ready int32
+	recheck := make(chan struct{})
+	tsk := asynctask.WaitFor(ctx, func(ctx context.Context) (bool, error) {
+		return atomic.LoadInt32(&ready) == 1, nil
+	}, &asynctask.WaitForOptions{Recheck: recheck})
+
+	recheck <- struct{}{}
+	assert.Equal(t, asynctask.StateRunning, tsk.State())
+
+	atomic.StoreInt32(&ready, 1)
+	recheck <- struct{}{}
+	_, err := tsk.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, asynctask.StateCompleted, tsk.State())
+}
+
+func TestWaitForErrorCase(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tsk := asynctask.WaitFor(ctx, func(ctx context.Context) (bool, error) {
+		return false, errors.New("dependency gone")
+	}, nil)
+	_, err := tsk.Wait(ctx)
+	assert.Equal(t, "dependency gone", err.Error())
+	assert.Equal(t, asynctask.StateFailed, tsk.State())
+
+	// closed recheck channel with no polling, only cancel can end it.
+	recheck := make(chan struct{})
+	close(recheck)
+	tsk = asynctask.WaitFor(ctx, func(ctx context.Context) (bool, error) {
+		return false, nil
+	}, &asynctask.WaitForOptions{Recheck: recheck})
+	_, err = tsk.WaitWithTimeout(ctx, 20*time.Millisecond)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	tsk.Cancel()
+	assert.Equal(t, asynctask.StateCanceled, tsk.State())
+}
@@ -0,0 +1,51 @@
+package asynctask
+
+import (
+	"context"
+	"fmt"
+)
+
+// WaitNOptions defines options for WaitN function
+type WaitNOptions struct {
+	// CancelRest set to true will cancel tasks not yet finished once n tasks finished.
+	CancelRest bool
+}
+
+// WaitN block current thread til n of the tasks finished, and returns their outcome in the order they finished.
+// errors from tasks are in the outcomes, error is returned only if ctx is done first (along with outcomes so far), or n is more than tasks passed in.
+// rest of the tasks keep running, unless options.CancelRest.
+func WaitN(ctx context.Context, n int, options *WaitNOptions, tasks ...*TaskStatus) ([]TaskResult, error) {
+	if n > len(tasks) {
+		return nil, fmt.Errorf("WaitN can't wait for %d out of %d tasks", n, len(tasks))
+	}
+	if options == nil {
+		options = &WaitNOptions{}
+	}
+
+	// buffered for every task, so late finishers never block.
+	finished := make(chan TaskResult, len(tasks))
+	for i, tsk := range tasks {
+		i, tsk := i, tsk
+		tsk.onDone(func() {
+			result, err := tsk.outcome()
+			finished <- TaskResult{Index: i, Task: tsk, Result: result, Err: err}
+		})
+	}
+
+	results := make([]TaskResult, 0, n)
+	for len(results) < n {
+		select {
+		case result := <-finished:
+			results = append(results, result)
+		case <-ctx.Done():
+			return results, fmt.Errorf("WaitN context canceled: %w", ctx.Err())
+		}
+	}
+
+	if options.CancelRest {
+		for _, tsk := range tasks {
+			tsk.Cancel()
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,63 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitN(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tasks := []*asynctask.TaskStatus{
+		asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond)),
+		asynctask.Start(ctx, getCountingTask(2, time.Millisecond)),
+		asynctask.Start(ctx, getErrorTask("dummy error", 20*time.Millisecond)),
+	}
+
+	results, err := asynctask.WaitN(ctx, 2, nil, tasks...)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 1, results[0].Index)
+	assert.Equal(t, 1, results[0].Result)
+	assert.Equal(t, 2, results[1].Index)
+	assert.Equal(t, "dummy error", results[1].Err.Error())
+	assert.Equal(t, asynctask.StateRunning, tasks[0].State(), "rest keep running")
+
+	results, err = asynctask.WaitN(ctx, 2, &asynctask.WaitNOptions{CancelRest: true}, tasks...)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, asynctask.StateCanceled, tasks[0].State())
+
+	_, err = asynctask.WaitN(ctx, 4, nil, tasks...)
+	assert.Error(t, err)
+
+	results, err = asynctask.WaitN(ctx, 0, nil, tasks...)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestWaitNContextCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	tasks := []*asynctask.TaskStatus{
+		asynctask.Start(ctx, getCountingTask(2, time.Millisecond)),
+		asynctask.Start(ctx, getCountingTask(10, 200*time.Millisecond)),
+	}
+	defer tasks[1].Cancel()
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelWait()
+	results, err := asynctask.WaitN(waitCtx, 2, nil, tasks...)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+	assert.Len(t, results, 1)
+	assert.Equal(t, 0, results[0].Index)
+}
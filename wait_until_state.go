@@ -0,0 +1,64 @@
+package asynctask
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrStateNotReached is returned by WaitUntilState if the task finished without reaching the state.
+var ErrStateNotReached = errors.New("state not reached")
+
+// lifecycleRank orders states a task goes through once, for WaitUntilState to tell whether a state was passed.
+// Retrying and Paused are not in the order, a task may never be in them.
+var lifecycleRank = map[State]int{
+	StateScheduled: 0,
+	StateQueued:    1,
+	StateRunning:   2,
+	StateRetrying:  2,
+	StatePaused:    2,
+	StateCompleted: 3,
+	StateFailed:    3,
+	StateCanceled:  3,
+	StateSkipped:   3,
+}
+
+// WaitUntilState block until the task is in state s, or passed it, without waiting for the task to finish.
+// Scheduled, Queued and Running are passed once task moves further along Scheduled -> Queued -> Running -> terminal,
+// (Running only if task actually started), other states have to be hit.
+// ErrStateNotReached is returned if the task finished without reaching s, ctx.Err() if ctx is done first.
+func (t *TaskStatus) WaitUntilState(ctx context.Context, s State) error {
+	changes, unsubscribe := t.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case state, ok := <-changes:
+			if !ok {
+				// we fell behind and terminal state was dropped, pick it up from the task.
+				state = t.State()
+			}
+			if state == s || t.passed(state, s) {
+				return nil
+			}
+			if state.IsTerminalState() {
+				return fmt.Errorf("%w: task is %s", ErrStateNotReached, state)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// passed tells whether task in state current has gone past s in its lifecycle.
+func (t *TaskStatus) passed(current, s State) bool {
+	if s != StateScheduled && s != StateQueued && s != StateRunning {
+		return false
+	}
+	if lifecycleRank[current] <= lifecycleRank[s] {
+		return false
+	}
+	if s == StateRunning {
+		return !t.Info().StartedAt.IsZero()
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package asynctask
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitUntilStateUnsubscribes(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFunc()
+
+	release := make(chan struct{})
+	tsk := Start(ctx, func(context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	defer close(release)
+
+	// waits giving up on their context leave no subscriber behind.
+	for i := 0; i < 3; i++ {
+		waitCtx, cancelWait := context.WithTimeout(ctx, time.Millisecond)
+		assert.Equal(t, context.DeadlineExceeded, tsk.WaitUntilState(waitCtx, StateCompleted))
+		cancelWait()
+	}
+	tsk.mutex.Lock()
+	defer tsk.mutex.Unlock()
+	assert.Empty(t, tsk.subscribers)
+}
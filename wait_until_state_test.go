@@ -0,0 +1,54 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitUntilState(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	attempts := 0
+	tsk := asynctask.Start(ctx, func(ctx context.Context) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("transient")
+		}
+		time.Sleep(20 * time.Millisecond)
+		return attempts, nil
+	}, asynctask.WithRetry(asynctask.RetryPolicy{MaxAttempts: 2, Backoff: 20 * time.Millisecond}))
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StateRetrying))
+	assert.Equal(t, asynctask.StateRetrying, tsk.State())
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StateCompleted))
+
+	// passed states return right away.
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StateRunning))
+	assert.NoError(t, tsk.WaitUntilState(ctx, asynctask.StateScheduled))
+	// states it never hit, and never will.
+	assert.True(t, errors.Is(tsk.WaitUntilState(ctx, asynctask.StatePaused), asynctask.ErrStateNotReached))
+	assert.True(t, errors.Is(tsk.WaitUntilState(ctx, asynctask.StateFailed), asynctask.ErrStateNotReached))
+
+	// failed in queue never ran.
+	pool := asynctask.NewPool(&asynctask.PoolOptions{Workers: 1})
+	defer pool.Close()
+	release := blockPool(ctx, pool, 1)
+	queued := pool.Submit(ctx, getCountingTask(1, time.Millisecond))
+	assert.NoError(t, queued.WaitUntilState(ctx, asynctask.StateQueued))
+	queued.Cancel()
+	release()
+	assert.True(t, errors.Is(queued.WaitUntilState(ctx, asynctask.StateRunning), asynctask.ErrStateNotReached))
+
+	// waiter gives up.
+	tsk = asynctask.Start(ctx, getCountingTask(10, 20*time.Millisecond))
+	defer tsk.Cancel()
+	waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancelWait()
+	assert.Equal(t, context.DeadlineExceeded, tsk.WaitUntilState(waitCtx, asynctask.StateCompleted))
+}
@@ -0,0 +1,110 @@
+package asynctask
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WorkflowStep is a named step of a Workflow.
+type WorkflowStep struct {
+	Name string
+	// Run does the step, previous is result of the step before, nil for the first step.
+	Run ContinueFunc
+	// Retry retries the step when it fails, nil means no retry.
+	Retry *RetryPolicy
+	// Timeout bounds each attempt of the step, zero means no timeout.
+	Timeout time.Duration
+}
+
+// Workflow is an ordered list of named steps run one after another as a task, lighter than a Graph for linear flows.
+type Workflow struct {
+	steps []WorkflowStep
+}
+
+// NewWorkflow returns a workflow running steps in order.
+func NewWorkflow(steps ...WorkflowStep) *Workflow {
+	return &Workflow{steps: steps}
+}
+
+// WorkflowRun is a handle to a running workflow.
+type WorkflowRun struct {
+	*TaskStatus
+	mutex  sync.Mutex
+	status WorkflowStatus
+}
+
+// WorkflowStatus tells where a workflow run is.
+type WorkflowStatus struct {
+	// Step is name of the step executing, or the last one executed once run finished, empty before the first step.
+	Step string
+	// Index is position of Step in the workflow, -1 before the first step.
+	Index int
+	// Attempt is the current (or last) attempt of Step, starting from 1.
+	Attempt int
+	// Completed has names of steps completed, in order.
+	Completed []string
+}
+
+// Start runs the steps in order as a task, result of the last step is result of the task.
+// a failed step (after its retries) fails the task with error naming the step, following steps don't run.
+func (w *Workflow) Start(ctx context.Context, opts ...TaskOption) *WorkflowRun {
+	run := &WorkflowRun{status: WorkflowStatus{Index: -1}}
+	run.TaskStatus = Start(ctx, func(fCtx context.Context) (interface{}, error) {
+		var result interface{}
+		for i, step := range w.steps {
+			run.update(func(status *WorkflowStatus) {
+				status.Step = step.Name
+				status.Index = i
+				status.Attempt = 0
+			})
+
+			var err error
+			result, err = run.runStep(fCtx, step, result)
+			if err != nil {
+				return nil, fmt.Errorf("step %q: %w", step.Name, err)
+			}
+			run.update(func(status *WorkflowStatus) {
+				status.Completed = append(status.Completed, step.Name)
+			})
+		}
+		return result, nil
+	}, opts...)
+	return run
+}
+
+// runStep runs a step, retrying as its policy allows, the task goes through StateRetrying between attempts.
+func (r *WorkflowRun) runStep(ctx context.Context, step WorkflowStep, previous interface{}) (interface{}, error) {
+	shouldRetry := func(err error) bool {
+		return step.Retry.ShouldRetry == nil || step.Retry.ShouldRetry(err)
+	}
+	return taskFromContext(ctx).retry(ctx, step.Retry, shouldRetry, func(ctx context.Context, attempt int) (interface{}, error) {
+		r.update(func(status *WorkflowStatus) {
+			status.Attempt = attempt
+		})
+
+		var result interface{}
+		err := runAttempt(ctx, step.Timeout, func(attemptCtx context.Context) error {
+			var err error
+			result, err = step.Run(attemptCtx, previous)
+			return err
+		})
+		return result, err
+	})
+}
+
+func (r *WorkflowRun) update(f func(status *WorkflowStatus)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	f(&r.status)
+}
+
+// Status returns where the run is, see WorkflowStatus.
+func (r *WorkflowRun) Status() WorkflowStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	status := r.status
+	status.Completed = append([]string(nil), r.status.Completed...)
+	return status
+}
@@ -0,0 +1,63 @@
+package asynctask_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-asynctask"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflow(t *testing.T) {
+	t.Parallel()
+	ctx, cancelFunc := newTestContextWithTimeout(t, 3*time.Second)
+	defer cancelFunc()
+
+	attempts := 0
+	configuring := make(chan struct{})
+	finish := make(chan struct{})
+	workflow := asynctask.NewWorkflow(
+		asynctask.WorkflowStep{Name: "createVM", Run: func(ctx context.Context, previous interface{}) (interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("quota")
+			}
+			return "vm-1", nil
+		}, Retry: &asynctask.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}},
+		asynctask.WorkflowStep{Name: "configure", Run: func(ctx context.Context, previous interface{}) (interface{}, error) {
+			close(configuring)
+			<-finish
+			return previous.(string) + " configured", nil
+		}},
+	)
+
+	run := workflow.Start(ctx, asynctask.WithName("provision"))
+	<-configuring
+	assert.Equal(t, asynctask.WorkflowStatus{Step: "configure", Index: 1, Attempt: 1, Completed: []string{"createVM"}}, run.Status())
+	close(finish)
+	result, err := run.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "vm-1 configured", result)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{"createVM", "configure"}, run.Status().Completed)
+
+	// step timeout fails the workflow, following steps don't run.
+	ran := false
+	run = asynctask.NewWorkflow(
+		asynctask.WorkflowStep{Name: "slow", Run: func(ctx context.Context, previous interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, Timeout: 5 * time.Millisecond, Retry: &asynctask.RetryPolicy{MaxAttempts: 2}},
+		asynctask.WorkflowStep{Name: "after", Run: func(ctx context.Context, previous interface{}) (interface{}, error) {
+			ran = true
+			return nil, nil
+		}},
+	).Start(ctx)
+	_, err = run.Wait(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expecting DeadlineExceeded")
+	assert.Equal(t, `step "slow": context deadline exceeded`, err.Error())
+	assert.False(t, ran)
+	assert.Equal(t, asynctask.WorkflowStatus{Step: "slow", Index: 0, Attempt: 2, Completed: nil}, run.Status())
+}